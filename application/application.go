@@ -3,12 +3,17 @@ package application
 import (
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/andskur/web-crawler/application/check"
+	"github.com/andskur/web-crawler/application/client"
 	"github.com/andskur/web-crawler/application/crawler"
+	"github.com/andskur/web-crawler/application/site"
 	"github.com/andskur/web-crawler/application/writer"
+	"github.com/andskur/web-crawler/application/writer/csv"
 	"github.com/andskur/web-crawler/config"
 )
 
@@ -16,9 +21,18 @@ var errInvalidMapType = errors.New("invalid sitemap type. Supported types:hash -
 
 // Application represent Crawler Application structure
 type Application struct {
-	*config.Config                  // configuration params
-	*crawler.Crawler                // web crawler instance
-	Writer           writer.IWriter // output writer instance
+	*config.Config                   // configuration params
+	*crawler.Crawler                 // web crawler instance
+	Writer           writer.IWriter  // main output writer instance, also writes broken links report
+	allowlist        check.Allowlist // known-bad urls ignored by check
+	outputs          []*output       // all outputs of the crawl result
+}
+
+// output represent crawl result output with its writer
+type output struct {
+	config.Destination
+	writer writer.IWriter // output writer instance
+	file   io.WriteCloser // output file of started pages stream
 }
 
 // NewApplication create new Web Crawler Application instance with
@@ -44,6 +58,11 @@ func (a *Application) initApp() error {
 		return err
 	}
 
+	// init Check allowlist
+	if err := a.initAllowlist(); err != nil {
+		return err
+	}
+
 	// init Logger
 	a.initLogger()
 
@@ -52,16 +71,64 @@ func (a *Application) initApp() error {
 
 // initWriter initialize Application Crawler instance
 func (a *Application) initCrawler() (err error) {
-	a.Crawler, err = crawler.NewCrawler(a.Target, a.Config.Verbose, a.Config.Semaphore)
+	a.Crawler, err = crawler.NewCrawler(a.Target, a.Config.Verbose, a.Config.Workers)
+	if err != nil {
+		return
+	}
+
+	// same user-agent is sent in requests and matched in robots.txt
+	opts := a.Config.Client
+	opts.UserAgent = a.Config.UserAgent
+	if a.Crawler.Client, err = client.NewClient(opts); err != nil {
+		return
+	}
+
+	a.Crawler.Order = a.Config.Order
+	a.Crawler.UserAgent = a.Config.UserAgent
+	a.Crawler.IgnoreRobots = a.Config.IgnoreRobots
+	a.Crawler.Rules = a.Config.Rules
+	a.Crawler.MaxDepth = a.Config.MaxDepth
+	a.Crawler.MaxPages = a.Config.MaxPages
+	a.Crawler.GracePeriod = a.Config.GracePeriod
+	a.Crawler.Checkpoint = a.Config.Checkpoint
+	a.Crawler.Interval = a.Config.Interval
+	a.Crawler.RateLimit = a.Config.RateLimit
+	a.Crawler.Delay = a.Config.Delay
+	a.Crawler.HostWorkers = a.Config.HostWorkers
+	a.Crawler.Retries = a.Config.Retries
+	a.Crawler.RetryBackoff = a.Config.RetryBackoff
+	a.Crawler.Progress = a.Config.Progress()
+	a.Crawler.Site.SetNormalizer(a.Config.Normalizer)
+	a.Crawler.Site.SetScope(a.Config.Scope)
+
+	// restore crawling state from previous run
+	if a.Config.Resume {
+		err = a.Crawler.LoadCheckpoint(a.Config.Checkpoint)
+	}
 	return
 }
 
-// initWriter initialize Application Output Writer instance
-func (a *Application) initWriter() (err error) {
-	a.Writer, err = writer.NewWriter(a.Output)
+// initAllowlist load check allowlist before crawling
+func (a *Application) initAllowlist() (err error) {
+	if a.Config.CheckMode && a.Config.Allowlist != "" {
+		a.allowlist, err = check.LoadAllowlist(a.Config.Allowlist, a.Config.Normalizer)
+	}
 	return
 }
 
+// initWriter initialize Application Output Writer instances
+func (a *Application) initWriter() error {
+	for _, dest := range a.Config.Destinations() {
+		wrt, err := writer.NewWriter(dest.Format, a.Config.WriterOptions)
+		if err != nil {
+			return err
+		}
+		a.outputs = append(a.outputs, &output{Destination: dest, writer: wrt})
+	}
+	a.Writer = a.outputs[0].writer
+	return nil
+}
+
 // initLogger initialize Application logger formatter
 func (a *Application) initLogger() {
 	formatter := &logrus.TextFormatter{}
@@ -75,31 +142,118 @@ func (a *Application) initLogger() {
 	logrus.SetFormatter(formatter)
 }
 
-// WriteOutput write Application output to file
+// BeginOutput start writing pages to output files while crawling,
+// for output formats supporting streaming
+func (a *Application) BeginOutput() (err error) {
+	var streams []writer.IStreamWriter
+	for _, out := range a.outputs {
+		stream, ok := out.writer.(writer.IStreamWriter)
+		if !ok {
+			continue
+		}
+
+		// stream is started with site view of the output map type
+		view, err := formatOutput(a.Site, out.Destination)
+		if err != nil {
+			return err
+		}
+		if out.file, err = writer.Create(out.Filename); err != nil {
+			return err
+		}
+		if err := stream.Begin(out.file, view); err != nil {
+			out.file.Close()
+			return err
+		}
+		streams = append(streams, stream)
+	}
+	if len(streams) == 0 {
+		return nil
+	}
+
+	// pages are written from crawling workers as soon as they are fetched
+	a.Crawler.OnPage = func(page *site.Page) {
+		for _, stream := range streams {
+			if err := stream.WritePage(page); err != nil {
+				page.Logger.Error(err)
+			}
+		}
+	}
+	return nil
+}
+
+// WriteOutput write Application outputs to files from
+// single crawl result, started pages streams are finished
 func (a *Application) WriteOutput() error {
-	if err := a.formatOutput(); err != nil {
-		return err
+	a.Crawler.OnPage = nil
+	for _, out := range a.outputs {
+		if err := a.writeOutput(out); err != nil {
+			return err
+		}
+
+		switch {
+		case a.Site.Incomplete:
+			fmt.Fprintf(a.Config.Progress(), "%s incomplete sitemap written to %s\n", strings.Title(out.MapType), writer.Name(out.Filename))
+		default:
+			fmt.Fprintf(a.Config.Progress(), "%s sitemap written to %s\n", strings.Title(out.MapType), writer.Name(out.Filename))
+		}
+
+		// edge list is accompanied by pages list file
+		if (out.Format == writer.CSV || out.Format == writer.TSV) && out.Filename != writer.Stdout {
+			fmt.Fprintf(a.Config.Progress(), "Pages list written to %s\n", csv.PagesFilename(out.Filename))
+		}
 	}
-	if err := a.Writer.WriteTo(a.Site, a.Filename); err != nil {
+	return nil
+}
+
+// writeOutput finish started pages stream
+// or write whole site to output file
+func (a *Application) writeOutput(out *output) error {
+	s, err := formatOutput(a.Site, out.Destination)
+	if err != nil {
 		return err
 	}
+	if out.file == nil {
+		return writer.WriteFile(out.writer, s, out.Filename)
+	}
 
-	fmt.Printf("%s sitemap written to %s\n", strings.Title(a.MapType), a.Filename)
+	if err := out.writer.(writer.IStreamWriter).End(s); err != nil {
+		out.file.Close()
+		return err
+	}
+	return out.file.Close()
+}
 
+// WriteBrokenLinks write broken links report to file
+func (a *Application) WriteBrokenLinks() error {
+	if a.Config.BrokenLinks == "" {
+		return nil
+	}
+	report := site.NewBrokenLinksReport(a.Site)
+	if err := writer.WriteFile(a.Writer, report, a.Config.BrokenLinks); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Config.Progress(), "%d broken links written to %s\n", report.Total, writer.Name(a.Config.BrokenLinks))
 	return nil
 }
 
-// TODO don't like output methods... Best way - interface and enum, like IWriter
+// RunCheck check crawled site for configured issues
+func (a *Application) RunCheck() *check.Result {
+	return check.Check(a.Site, a.Config.FailOn, a.allowlist)
+}
 
-// FormatOutput format application output after execution
-func (a *Application) formatOutput() error {
-	switch a.MapType {
-	case "hash":
-		a.Site.PageTree = nil
-	case "tree":
-		a.Site.HashMap = nil
+// formatOutput return crawled site view for given output,
+// crawled site is shared by all outputs and stays unchanged
+func formatOutput(s *site.Site, dest config.Destination) (*site.Site, error) {
+	view := *s
+	switch {
+	case dest.MapType != "hash" && dest.MapType != "tree":
+		return nil, errInvalidMapType
+	case dest.Format == writer.HTML:
+		// html report renders both page tree and pages table
+	case dest.MapType == "hash":
+		view.PageTree = nil
 	default:
-		return errInvalidMapType
+		view.HashMap = nil
 	}
-	return nil
+	return &view, nil
 }
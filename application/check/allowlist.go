@@ -0,0 +1,45 @@
+package check
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+// Allowlist represent set of known-bad urls ignored by check
+type Allowlist map[string]bool
+
+// LoadAllowlist read allowlist from given file,
+// urls are keyed by given normalizer
+func LoadAllowlist(fileName string, normalizer *site.Normalizer) (Allowlist, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseAllowlist(file, normalizer)
+}
+
+// ParseAllowlist parse allowlist with one url per line keyed by given normalizer,
+// empty lines and lines starting with # are ignored
+func ParseAllowlist(r io.Reader, normalizer *site.Normalizer) (Allowlist, error) {
+	allowlist := make(Allowlist)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		allowlist[normalizer.KeyString(line)] = true
+	}
+	return allowlist, scanner.Err()
+}
+
+// Allowed check if given url is in allowlist, urls are compared
+// in canonical form of the normalizer allowlist is parsed with
+func (a Allowlist) Allowed(url string, normalizer *site.Normalizer) bool {
+	return a[normalizer.KeyString(url)]
+}
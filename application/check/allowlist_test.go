@@ -0,0 +1,44 @@
+package check
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+func TestAllowlist_Allowed(t *testing.T) {
+	content := `
+# known broken pages
+https://monzo.com/old-blog/
+https://monzo.com/legacy
+`
+	strict := &site.Normalizer{}
+
+	tests := []struct {
+		name       string
+		normalizer *site.Normalizer
+		url        string
+		want       bool
+	}{
+		{"exact", nil, "https://monzo.com/legacy", true},
+		{"trailingSlash", nil, "https://monzo.com/old-blog", true},
+		{"notListed", nil, "https://monzo.com/blog", false},
+		{"comment", nil, "# known broken pages", false},
+		{"strictExact", strict, "https://monzo.com/old-blog/", true},
+		{"strictTrailingSlash", strict, "https://monzo.com/old-blog", false},
+		{"anyScheme", &site.Normalizer{IgnoreScheme: true}, "http://monzo.com/legacy", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowlist, err := ParseAllowlist(strings.NewReader(content), tt.normalizer)
+			if err != nil {
+				t.Errorf("ParseAllowlist() error = %v", err)
+				return
+			}
+			if got := allowlist.Allowed(tt.url, tt.normalizer); got != tt.want {
+				t.Errorf("Allowlist.Allowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package check
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+// Check exit codes
+const (
+	ExitOK         = 0 // no issues found
+	ExitIssues     = 1 // issues found
+	ExitError      = 2 // invalid arguments or crawling error
+	ExitIncomplete = 3 // no issues found, but crawling was interrupted
+)
+
+// maxPrintedSources is maximum source pages printed for one issue
+const maxPrintedSources = 3
+
+// Issue represent site page matched check condition
+type Issue struct {
+	Condition Condition     // matched check condition
+	Url       string        // page Url
+	Detail    string        // issue description
+	Sources   []site.Source // pages linking to page
+}
+
+// Result represent site check result
+type Result struct {
+	Url        string  // checked site Url
+	Pages      int     // total crawled pages count
+	Issues     []Issue // found issues sorted by condition and url
+	Ignored    int     // issues ignored by allowlist
+	Incomplete bool    // crawling was interrupted before finish
+}
+
+// Check find pages of crawled site matching given conditions,
+// pages from allowlist are ignored
+func Check(s *site.Site, failOn []Condition, allowlist Allowlist) *Result {
+	result := &Result{
+		Url:        s.Url.String(),
+		Pages:      s.GetTotalPages(),
+		Incomplete: s.Incomplete,
+	}
+
+	enabled := make(map[Condition]bool, len(failOn))
+	for _, condition := range failOn {
+		enabled[condition] = true
+	}
+
+	normalizer := s.Normalizer()
+	for url, page := range s.HashMap {
+		for _, issue := range pageIssues(url, page) {
+			if !enabled[issue.Condition] {
+				continue
+			}
+			if allowlist.Allowed(url, normalizer) {
+				result.Ignored++
+				continue
+			}
+			issue.Sources = s.PageSources(url)
+			result.Issues = append(result.Issues, issue)
+		}
+	}
+
+	sort.Slice(result.Issues, func(i, j int) bool {
+		if result.Issues[i].Condition != result.Issues[j].Condition {
+			return result.Issues[i].Condition < result.Issues[j].Condition
+		}
+		return result.Issues[i].Url < result.Issues[j].Url
+	})
+	return result
+}
+
+// pageIssues return all issues of given site page
+func pageIssues(url string, page *site.Page) []Issue {
+	response := page.Response
+	loop := response != nil && response.Loop()
+
+	var issues []Issue
+	switch {
+	case page.State == site.Failed && loop:
+		issues = append(issues, Issue{Condition: Loop, Url: url, Detail: "redirect loop " + redirectsChain(response)})
+	case page.State == site.Failed:
+		issues = append(issues, Issue{Condition: Broken, Url: url, Detail: page.Error})
+	case page.State == site.Redirected:
+		issues = append(issues, Issue{Condition: External, Url: url, Detail: "redirects to " + response.FinalUrl})
+	}
+
+	// internal redirects of fetched pages
+	if response != nil && len(response.Redirects) > 0 && !loop && page.State != site.Redirected {
+		issues = append(issues, Issue{Condition: Redirect, Url: url, Detail: "redirects to " + response.FinalUrl})
+	}
+	return issues
+}
+
+// redirectsChain format redirects chain of given response
+func redirectsChain(response *site.Response) string {
+	chain := make([]string, 0, len(response.Redirects)+1)
+	for _, redirect := range response.Redirects {
+		chain = append(chain, redirect.Url)
+	}
+	chain = append(chain, response.Redirects[len(response.Redirects)-1].Location)
+	return strings.Join(chain, " -> ")
+}
+
+// Print write concise check summary to given writer
+func (r *Result) Print(w io.Writer) {
+	fmt.Fprintf(w, "Checked %d pages at %s\n", r.Pages, r.Url)
+
+	for _, issue := range r.Issues {
+		fmt.Fprintf(w, "%-9s %s: %s\n", issue.Condition, issue.Url, issue.Detail)
+		if len(issue.Sources) == 0 {
+			continue
+		}
+
+		sources := make([]string, 0, maxPrintedSources)
+		for i, source := range issue.Sources {
+			if i == maxPrintedSources {
+				sources = append(sources, fmt.Sprintf("and %d more", len(issue.Sources)-maxPrintedSources))
+				break
+			}
+			sources = append(sources, fmt.Sprintf("%s (%q)", source.Url, source.Text))
+		}
+		fmt.Fprintf(w, "%-9s linked from %s\n", "", strings.Join(sources, ", "))
+	}
+
+	summary := fmt.Sprintf("%d issues found", len(r.Issues))
+	if r.Ignored > 0 {
+		summary += fmt.Sprintf(", %d ignored by allowlist", r.Ignored)
+	}
+	if r.Incomplete {
+		summary += ", crawling was interrupted"
+	}
+	fmt.Fprintln(w, summary)
+}
+
+// ExitCode return process exit code of check result
+func (r *Result) ExitCode() int {
+	switch {
+	case len(r.Issues) > 0:
+		return ExitIssues
+	case r.Incomplete:
+		return ExitIncomplete
+	default:
+		return ExitOK
+	}
+}
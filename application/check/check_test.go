@@ -0,0 +1,121 @@
+package check
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		failOn       []Condition
+		allowlist    Allowlist
+		want         []string
+		wantIgnored  int
+		wantExitCode int
+	}{
+		{"broken", []Condition{Broken}, nil, []string{"broken https://monzo.com/missing"}, 0, ExitIssues},
+		{"all", []Condition{Broken, Loop, Redirect, External}, nil, []string{
+			"broken https://monzo.com/missing",
+			"loop https://monzo.com/loop",
+			"redirect https://monzo.com/old",
+			"external https://monzo.com/shop",
+		}, 0, ExitIssues},
+		{"allowlist", []Condition{Broken, Loop}, Allowlist{"https://monzo.com/missing": true}, []string{
+			"loop https://monzo.com/loop",
+		}, 1, ExitIssues},
+		{"passed", []Condition{Broken}, Allowlist{"https://monzo.com/missing": true}, nil, 1, ExitOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Check(getTestSite(), tt.failOn, tt.allowlist)
+
+			var got []string
+			for _, issue := range result.Issues {
+				got = append(got, issue.Condition.String()+" "+issue.Url)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() issues = %v, want %v", got, tt.want)
+			}
+			if result.Ignored != tt.wantIgnored {
+				t.Errorf("Check() ignored = %v, want %v", result.Ignored, tt.wantIgnored)
+			}
+			if code := result.ExitCode(); code != tt.wantExitCode {
+				t.Errorf("Result.ExitCode() = %v, want %v", code, tt.wantExitCode)
+			}
+		})
+	}
+}
+
+func TestResult_Print(t *testing.T) {
+	result := Check(getTestSite(), []Condition{Broken, Loop}, nil)
+	result.Incomplete = true
+
+	var buf bytes.Buffer
+	result.Print(&buf)
+	want := `Checked 3 pages at https://monzo.com
+broken    https://monzo.com/missing: server responded 404 Not Found
+          linked from https://monzo.com ("Missing"), https://monzo.com/old ("Gone")
+loop      https://monzo.com/loop: redirect loop https://monzo.com/loop -> https://monzo.com/loop/ -> https://monzo.com/loop
+2 issues found, crawling was interrupted
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Result.Print() = %s, want %s", got, want)
+	}
+}
+
+func TestResult_ExitCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		result Result
+		want   int
+	}{
+		{"ok", Result{}, ExitOK},
+		{"issues", Result{Issues: []Issue{{}}, Incomplete: true}, ExitIssues},
+		{"incomplete", Result{Incomplete: true}, ExitIncomplete},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.ExitCode(); got != tt.want {
+				t.Errorf("Result.ExitCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// getTestSite return crawled site with broken page,
+// redirect loop, internal and external redirects
+func getTestSite() *site.Site {
+	url, _ := site.ParseRequestURI("https://monzo.com")
+	s := site.NewSite(url)
+	s.TotalPages = 3
+	s.PageTree.State = site.Crawled
+
+	addTestPage(s, "/missing", site.Failed, "server responded 404 Not Found", &site.Response{Status: 404})
+	addTestPage(s, "/loop", site.Failed, "redirect loop", &site.Response{Status: 302, Redirects: []*site.Redirect{
+		{Url: "https://monzo.com/loop", Status: 302, Location: "https://monzo.com/loop/"},
+		{Url: "https://monzo.com/loop/", Status: 302, Location: "https://monzo.com/loop"},
+	}})
+	addTestPage(s, "/old", site.Crawled, "", &site.Response{Status: 200, FinalUrl: "https://monzo.com/new", Redirects: []*site.Redirect{
+		{Url: "https://monzo.com/old", Status: 301, Location: "https://monzo.com/new"},
+	}})
+	addTestPage(s, "/shop", site.Redirected, "", &site.Response{Status: 302, FinalUrl: "https://shop.monzo.com/", Redirects: []*site.Redirect{
+		{Url: "https://monzo.com/shop", Status: 302, Location: "https://shop.monzo.com/"},
+	}})
+
+	s.AddSource("https://monzo.com/missing", site.Source{Url: "https://monzo.com/old", Text: "Gone"})
+	s.AddSource("https://monzo.com/missing/", site.Source{Url: "https://monzo.com", Text: "Missing"})
+	return s
+}
+
+// addTestPage add page with given state and response to site
+func addTestPage(s *site.Site, path string, state site.PageState, err string, response *site.Response) {
+	page, _ := s.PageTree.AddSubPage(path)
+	page.State = state
+	page.Error = err
+	page.Response = response
+	s.AddPageToSite(page)
+}
@@ -0,0 +1,56 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Condition is Enum that represent
+// site issue which fails the check
+type Condition int
+
+// available check Condition constants
+const (
+	Broken   Condition = iota // page responded 4xx/5xx or failed at network level
+	Loop                      // page redirects in a loop
+	Redirect                  // link to page which redirects to other site page
+	External                  // link to page which redirects to external host
+	unsupportedCondition
+)
+
+// conditions is slice of check conditions string representations
+var conditions = [...]string{
+	Broken:   "broken",
+	Loop:     "loop",
+	Redirect: "redirect",
+	External: "external",
+}
+
+// String return check condition enum as a string
+func (c Condition) String() string {
+	return conditions[c]
+}
+
+// ParseCondition return new Condition enum from given string
+func ParseCondition(s string) (Condition, error) {
+	for i, r := range conditions {
+		if s == r {
+			return Condition(i), nil
+		}
+	}
+	return unsupportedCondition, fmt.Errorf("invalid check Condition value %q", s)
+}
+
+// ParseConditions return Condition enums
+// from given comma-separated string
+func ParseConditions(s string) ([]Condition, error) {
+	var parsed []Condition
+	for _, value := range strings.Split(s, ",") {
+		condition, err := ParseCondition(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, condition)
+	}
+	return parsed, nil
+}
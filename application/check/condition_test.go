@@ -0,0 +1,31 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseConditions(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []Condition
+		wantErr bool
+	}{
+		{"one", "broken", []Condition{Broken}, false},
+		{"many", "broken, loop,external", []Condition{Broken, Loop, External}, false},
+		{"invalid", "broken,slow", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseConditions(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseConditions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseConditions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
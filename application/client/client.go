@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var errInvalidCA = errors.New("no valid certificates in CA bundle")
+
+// Options represent crawler HTTP client parameters
+type Options struct {
+	ConnectTimeout time.Duration // maximum time of connection establishing, 0 - unlimited
+	ReadTimeout    time.Duration // maximum time of waiting data from server, 0 - unlimited
+	Timeout        time.Duration // maximum total request duration, 0 - unlimited
+	UserAgent      string        // User-Agent header value
+	Headers        http.Header   // extra static headers of every request
+	Proxy          *url.URL      // HTTP(S) proxy, nil - proxy from environment
+	CABundle       string        // file with PEM encoded trusted CA certificates
+	Insecure       bool          // skip server certificate verification
+}
+
+// NewClient create new HTTP client with given options
+func NewClient(opts Options) (*http.Client, error) {
+	// trusted certificates
+	tlsConfig := &tls.Config{InsecureSkipVerify: opts.Insecure}
+	if opts.CABundle != "" {
+		pool, err := loadCABundle(opts.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// proxy from flags or from HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	proxy := http.ProxyFromEnvironment
+	if opts.Proxy != nil {
+		proxy = http.ProxyURL(opts.Proxy)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   opts.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialContext(dialer, opts.ReadTimeout),
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   opts.ConnectTimeout,
+		ResponseHeaderTimeout: opts.ReadTimeout,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	return &http.Client{
+		Transport: &headerTransport{
+			transport: transport,
+			userAgent: opts.UserAgent,
+			headers:   opts.Headers,
+		},
+		Timeout: opts.Timeout,
+	}, nil
+}
+
+// headerTransport set User-Agent and static headers
+// to every request before sending it
+type headerTransport struct {
+	transport http.RoundTripper
+	userAgent string
+	headers   http.Header
+}
+
+// RoundTrip send request copy with headers
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, values := range t.headers {
+		req.Header[name] = values
+	}
+	if t.userAgent != "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// dialContext return dial function which
+// limits waiting time of every connection read
+func dialContext(dialer *net.Dialer, readTimeout time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil || readTimeout <= 0 {
+			return conn, err
+		}
+		return &timeoutConn{Conn: conn, timeout: readTimeout}, nil
+	}
+}
+
+// timeoutConn is connection with read deadline
+// extended before every read
+type timeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+// Read read data from connection, fail if
+// no data is received during read timeout
+func (c *timeoutConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(b)
+}
+
+// loadCABundle create certificates pool from system
+// certificates and certificates from given PEM file
+func loadCABundle(fileName string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s: %s", fileName, errInvalidCA)
+	}
+	return pool, nil
+}
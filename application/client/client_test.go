@@ -0,0 +1,151 @@
+package client
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewClient_headers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s|%s", r.Header.Get("User-Agent"), r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{
+		UserAgent: "web-crawler/1.0",
+		Headers:   http.Header{"Authorization": {"Bearer token"}},
+	})
+	if err != nil {
+		t.Errorf("NewClient() error = %v", err)
+		return
+	}
+
+	if got := getTestBody(t, client, server.URL); got != "web-crawler/1.0|Bearer token" {
+		t.Errorf("NewClient() request headers = %v, want %v", got, "web-crawler/1.0|Bearer token")
+	}
+}
+
+func TestNewClient_timeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"noTimeouts", Options{}, false},
+		{"readTimeout", Options{ReadTimeout: 50 * time.Millisecond}, true},
+		{"totalTimeout", Options{Timeout: 50 * time.Millisecond}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.opts)
+			if err != nil {
+				t.Errorf("NewClient() error = %v", err)
+				return
+			}
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewClient_tls(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	// write self-signed server certificate to CA bundle
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caBundle, cert, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"untrusted", Options{}, true},
+		{"caBundle", Options{CABundle: caBundle}, false},
+		{"insecure", Options{Insecure: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.opts)
+			if err != nil {
+				t.Errorf("NewClient() error = %v", err)
+				return
+			}
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewClient_invalidCABundle(t *testing.T) {
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	if err := ioutil.WriteFile(caBundle, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewClient(Options{CABundle: caBundle}); err == nil {
+		t.Errorf("NewClient() invalid CA bundle is accepted")
+	}
+}
+
+func TestNewClient_proxy(t *testing.T) {
+	// proxy receives requests with absolute target url
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "proxied %s", r.URL)
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	client, err := NewClient(Options{Proxy: proxyURL})
+	if err != nil {
+		t.Errorf("NewClient() error = %v", err)
+		return
+	}
+
+	if got := getTestBody(t, client, "http://monzo.com/about"); got != "proxied http://monzo.com/about" {
+		t.Errorf("NewClient() proxy response = %v, want %v", got, "proxied http://monzo.com/about")
+	}
+}
+
+// getTestBody request given url and return response body
+func getTestBody(t *testing.T, client *http.Client, url string) string {
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
@@ -0,0 +1,164 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+// benchPages is number of pages in benchmark test site
+const benchPages = 3000
+
+// benchConcurrency is concurrency levels compared in benchmarks
+var benchConcurrency = []int{4, 16, 64}
+
+func BenchmarkCrawler_WorkerPool(b *testing.B) {
+	server := getBenchServer(benchPages)
+	defer server.Close()
+
+	for _, workers := range benchConcurrency {
+		b.Run(strconv.Itoa(workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				c, _ := NewCrawler(getTestSite(server.URL).Url, false, workers)
+				c.IgnoreRobots = true
+				if err := c.StartCrawling(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+				if c.Site.TotalPages != benchPages {
+					b.Fatalf("crawled %d pages, want %d", c.Site.TotalPages, benchPages)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCrawler_LegacySemaphore(b *testing.B) {
+	server := getBenchServer(benchPages)
+	defer server.Close()
+
+	for _, workers := range benchConcurrency {
+		b.Run(strconv.Itoa(workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				c := newLegacyCrawler(getTestSite(server.URL), workers)
+				c.wg.Add(1)
+				<-c.semaphore
+				c.crawlPage(c.site.PageTree)
+				c.wg.Wait()
+				if c.site.TotalPages != benchPages {
+					b.Fatalf("crawled %d pages, want %d", c.site.TotalPages, benchPages)
+				}
+			}
+		})
+	}
+}
+
+// legacyCrawler reproduce previous goroutine per link crawler
+// with semaphore busy-wait loop, used as benchmarks baseline
+type legacyCrawler struct {
+	site      *site.Site
+	semaphore chan int
+	wg        sync.WaitGroup
+}
+
+// newLegacyCrawler create legacyCrawler with filled up semaphore
+func newLegacyCrawler(s *site.Site, capacity int) *legacyCrawler {
+	c := &legacyCrawler{site: s, semaphore: make(chan int, capacity)}
+	for i := 0; i < capacity; i++ {
+		c.semaphore <- 1
+	}
+	return c
+}
+
+// crawlPage is copy of previous Crawler.CrawlPage implementation
+func (c *legacyCrawler) crawlPage(page *site.Page) {
+	defer c.wg.Done()
+
+	resp, err := http.Get(page.Url.String())
+	if err != nil {
+		c.semaphore <- 1
+		return
+	}
+	defer resp.Body.Close()
+
+	c.semaphore <- 1
+
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/html") {
+		c.site.DeletePageFromSite(page.Url.String())
+		return
+	}
+
+	c.site.IncTotalPages()
+
+	tokens := html.NewTokenizer(resp.Body)
+	for {
+		switch tokens.Next() {
+		case html.ErrorToken:
+			return
+		case html.StartTagToken:
+			token := tokens.Token()
+			if token.Data != "a" {
+				continue
+			}
+			link, ok := getLink(token)
+			if !ok {
+				continue
+			}
+			childPage, err := page.AddSubPage(link)
+			if err != nil {
+				continue
+			}
+			if err := c.site.AddPageToSite(childPage); err != nil {
+				continue
+			}
+
+		CrawlChild:
+			select {
+			case <-c.semaphore:
+				c.wg.Add(1)
+				go c.crawlPage(childPage)
+			default:
+				time.Sleep(10 * time.Millisecond)
+				goto CrawlChild
+			}
+		}
+	}
+}
+
+// getBenchServer create test web site with given pages count
+func getBenchServer(pages int) *httptest.Server {
+	return httptest.NewServer(getBenchHandler(pages))
+}
+
+// getBenchHandler create test web site handler with given pages count,
+// every page link to its two child pages, root and few random pages
+func getBenchHandler(pages int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := 0
+		if r.URL.Path != "/" {
+			var err error
+			if id, err = strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/page/")); err != nil || id >= pages {
+				http.NotFound(w, r)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><body><a href="/">Home</a>`)
+		for _, child := range []int{2*id + 1, 2*id + 2, (id * 7) % pages, (id * 13) % pages} {
+			if child > 0 && child < pages {
+				fmt.Fprintf(w, `<p>Some text</p><a href="/page/%d">Page %d</a>`, child, child)
+			}
+		}
+		fmt.Fprint(w, `</body></html>`)
+	})
+}
@@ -0,0 +1,251 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+// checkpoint represent saved crawling state:
+// crawled site pages, skipped pages and crawling queue
+type checkpoint struct {
+	Url        string                   `json:"url"`         // site entry page
+	TotalPages int                      `json:"total_pages"` // total crawled pages count
+	Fetched    int                      `json:"fetched"`     // number of started page fetches
+	Limits     []string                 `json:"limits"`      // reached crawl limits
+	Skipped    map[string]string        `json:"skipped"`     // skipped pages with reasons
+	Excluded   map[string]string        `json:"excluded"`    // excluded pages with matched rules
+	Sources    map[string][]site.Source `json:"sources"`     // source pages of every linked page
+	Pages      []checkpointPage         `json:"pages"`       // site pages in page tree order
+	Frontier   []string                 `json:"frontier"`    // pages waiting for crawling
+}
+
+// checkpointPage represent saved site hash map page
+// with parent page url in page tree
+type checkpointPage struct {
+	Url      string         `json:"url"`
+	Parent   string         `json:"parent,omitempty"`
+	Depth    int            `json:"depth"`
+	State    site.PageState `json:"state"`
+	Attempts int            `json:"attempts,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	Response *site.Response `json:"response,omitempty"`
+	Links    []string       `json:"links,omitempty"`
+}
+
+// checkpoints periodically save crawling state until done channel is closed
+func (c *Crawler) checkpoints(done <-chan struct{}, finished chan<- struct{}) {
+	defer close(finished)
+	if c.Checkpoint == "" || c.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			// wait pages in progress to save consistent state
+			c.frontier.pause()
+			err := c.SaveCheckpoint(c.Checkpoint)
+			c.frontier.resume()
+			if err != nil && c.Verbose {
+				c.Site.PageTree.Logger.WithField("checkpoint", c.Checkpoint).Error(err)
+			}
+		}
+	}
+}
+
+// finishCheckpoint save final crawling state if crawling was interrupted
+// or some pages are not fetched, remove state file otherwise
+func (c *Crawler) finishCheckpoint() error {
+	if c.Checkpoint == "" {
+		return nil
+	}
+	if len(c.deferred) > 0 {
+		if err := c.SaveCheckpoint(c.Checkpoint); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.Progress, "Crawling state saved to %s\n", c.Checkpoint)
+		return nil
+	}
+	if err := os.Remove(c.Checkpoint); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// SaveCheckpoint write current crawling state to given file.
+// Crawling should be paused or finished.
+func (c *Crawler) SaveCheckpoint(fileName string) error {
+	cp := checkpoint{
+		Url:        c.Site.Url.String(),
+		TotalPages: c.Site.GetTotalPages(),
+		Skipped:    make(map[string]string),
+		Excluded:   make(map[string]string),
+	}
+
+	c.mu.Lock()
+	cp.Fetched = c.fetched
+	queue := c.queue()
+	c.mu.Unlock()
+
+	// pages limit is checked again on resume
+	for _, limit := range c.Site.Limits {
+		if limit != site.LimitPages {
+			cp.Limits = append(cp.Limits, limit)
+		}
+	}
+	for url, reason := range c.Site.Skipped {
+		cp.Skipped[url] = reason
+	}
+	for url, rule := range c.Site.Excluded {
+		cp.Excluded[url] = rule
+	}
+	cp.Sources = c.Site.Sources
+	for _, page := range queue {
+		cp.Frontier = append(cp.Frontier, page.Url.String())
+	}
+
+	// walk page tree only through pages from hash map
+	if c.isSitePage(c.Site.PageTree) {
+		cp.Pages = c.savePages(cp.Pages, c.Site.PageTree, "")
+	}
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	// write to temporary file first to not corrupt previous checkpoint
+	tmp := fileName + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fileName)
+}
+
+// LoadCheckpoint restore crawling state from given file,
+// restored crawling queue is crawled on next StartCrawling
+func (c *Crawler) LoadCheckpoint(fileName string) error {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return err
+	}
+	if cp.Url != c.Site.Url.String() {
+		return fmt.Errorf("checkpoint %s belongs to other site %s", fileName, cp.Url)
+	}
+
+	c.Site.TotalPages = cp.TotalPages
+	c.Site.Limits = cp.Limits
+	c.fetched = cp.Fetched
+	for url, reason := range cp.Skipped {
+		c.Site.SkipPage(url, reason)
+	}
+	for url, rule := range cp.Excluded {
+		c.Site.ExcludePage(url, rule)
+	}
+	for url, sources := range cp.Sources {
+		c.Site.Sources[url] = sources
+	}
+
+	// restore page tree starting from entry page
+	records := make(map[string]checkpointPage, len(cp.Pages))
+	for _, record := range cp.Pages {
+		records[record.Url] = record
+	}
+	if record, ok := records[c.Site.Url.String()]; ok {
+		c.restorePage(c.Site.PageTree, record, records)
+	}
+
+	// restore crawling queue
+	c.restored = make([]*site.Page, 0, len(cp.Frontier))
+	for _, url := range cp.Frontier {
+		page, ok := c.Site.HashMap[url]
+		if !ok {
+			return fmt.Errorf("checkpoint queued page %s is not found", url)
+		}
+		page.State = site.Queued
+		c.restored = append(c.restored, page)
+	}
+	return nil
+}
+
+// queue return pages waiting for crawling in popping order,
+// deferred pages were popped before queued ones
+func (c *Crawler) queue() []*site.Page {
+	queued := c.frontier.queued()
+	if c.Order == DFS {
+		pages := queued
+		for i := len(c.deferred) - 1; i >= 0; i-- {
+			pages = append(pages, c.deferred[i])
+		}
+		return pages
+	}
+	return append(append([]*site.Page{}, c.deferred...), queued...)
+}
+
+// savePages append given page and all its child
+// hash map pages to checkpoint pages slice
+func (c *Crawler) savePages(pages []checkpointPage, page *site.Page, parent string) []checkpointPage {
+	record := checkpointPage{
+		Url:      page.Url.String(),
+		Parent:   parent,
+		Depth:    page.Depth,
+		State:    page.State,
+		Attempts: page.Attempts,
+		Error:    page.Error,
+		Response: page.Response,
+	}
+	for _, link := range page.Links {
+		record.Links = append(record.Links, link.Url.String())
+	}
+	pages = append(pages, record)
+
+	for _, link := range page.Links {
+		if c.isSitePage(link) {
+			pages = c.savePages(pages, link, record.Url)
+		}
+	}
+	return pages
+}
+
+// restorePage restore given page fields and its child pages,
+// child pages which belong to given parent are restored recursively
+func (c *Crawler) restorePage(page *site.Page, record checkpointPage, records map[string]checkpointPage) {
+	page.Depth = record.Depth
+	page.State = record.State
+	page.Attempts = record.Attempts
+	page.Error = record.Error
+	page.Response = record.Response
+	c.Site.HashMap[record.Url] = page
+
+	for _, link := range record.Links {
+		child, err := page.AddSubPage(link)
+		if err != nil {
+			continue
+		}
+
+		if childRecord, ok := records[link]; ok && childRecord.Parent == record.Url {
+			c.restorePage(child, childRecord, records)
+		} else {
+			child.State = c.Site.LinkState(link)
+		}
+	}
+}
+
+// isSitePage check if given tree page is the page stored in site hash map
+func (c *Crawler) isSitePage(page *site.Page) bool {
+	return c.Site.HashMap[page.Url.String()] == page
+}
@@ -0,0 +1,131 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andskur/web-crawler/application/rules"
+)
+
+func TestCrawler_LoadCheckpoint(t *testing.T) {
+	// slow down responses to interrupt crawling in the middle
+	handler := getBenchHandler(200)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	// links to excluded pages are restored with excluded state
+	crawlRules, err := rules.Parse(strings.NewReader("exclude re:7$"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// expected output of uninterrupted crawling
+	c, _ := NewCrawler(getTestSite(server.URL).Url, true, 1)
+	c.IgnoreRobots = true
+	c.Rules = crawlRules
+	c.StartCrawling(context.Background())
+	want := getTestOutput(t, c)
+
+	tests := []struct {
+		name     string
+		maxPages int
+		timeout  time.Duration
+		interval time.Duration
+	}{
+		{"pagesLimit", 50, 0, time.Minute},
+		{"interrupted", 0, 30 * time.Millisecond, time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "crawl.checkpoint")
+
+			// interrupted crawling with checkpoints
+			c, _ := NewCrawler(getTestSite(server.URL).Url, true, 1)
+			c.IgnoreRobots = true
+			c.Rules = crawlRules
+			c.MaxPages = tt.maxPages
+			c.Checkpoint = fileName
+			c.Interval = tt.interval
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			if err := c.StartCrawling(ctx); err != nil {
+				t.Errorf("Crawler.StartCrawling() error = %v", err)
+				return
+			}
+			if c.Site.TotalPages == 200 {
+				t.Errorf("Crawler.StartCrawling() crawling is not interrupted")
+				return
+			}
+
+			// resumed crawling
+			resumed, _ := NewCrawler(getTestSite(server.URL).Url, true, 1)
+			resumed.IgnoreRobots = true
+			resumed.Rules = crawlRules
+			resumed.Checkpoint = fileName
+			resumed.Interval = time.Minute
+			if err := resumed.LoadCheckpoint(fileName); err != nil {
+				t.Errorf("Crawler.LoadCheckpoint() error = %v", err)
+				return
+			}
+			if err := resumed.StartCrawling(context.Background()); err != nil {
+				t.Errorf("Crawler.StartCrawling() error = %v", err)
+				return
+			}
+
+			if got := getTestOutput(t, resumed); got != want {
+				t.Errorf("Crawler.LoadCheckpoint() resumed output differ from uninterrupted:\n%s\nwant:\n%s", got, want)
+			}
+			if _, err := os.Stat(fileName); !os.IsNotExist(err) {
+				t.Errorf("Crawler.StartCrawling() checkpoint file is not removed after finish")
+			}
+		})
+	}
+}
+
+func TestCrawler_LoadCheckpoint_otherSite(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	fileName := filepath.Join(t.TempDir(), "crawl.checkpoint")
+
+	c, _ := NewCrawler(getTestSite(server.URL).Url, true, 1)
+	c.frontier = newFrontier(BFS)
+	if err := c.SaveCheckpoint(fileName); err != nil {
+		t.Errorf("Crawler.SaveCheckpoint() error = %v", err)
+		return
+	}
+
+	other, _ := NewCrawler(getTestSite("https://monzo.com").Url, true, 1)
+	if err := other.LoadCheckpoint(fileName); err == nil {
+		t.Errorf("Crawler.LoadCheckpoint() checkpoint of other site is loaded")
+	}
+}
+
+// getTestOutput marshal crawled site page tree and hash map
+// without response times, which differ between crawlings
+func getTestOutput(t *testing.T, c *Crawler) string {
+	for _, page := range c.Site.HashMap {
+		if page.Response != nil {
+			page.Response.ResponseTime = 0
+		}
+	}
+	data, err := json.Marshal(c.Site)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -1,55 +1,117 @@
 package crawler
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"os"
 	"strings"
 	"sync"
 	"time"
 
 	"golang.org/x/net/html"
 
+	"github.com/andskur/web-crawler/application/robots"
+	"github.com/andskur/web-crawler/application/rules"
 	"github.com/andskur/web-crawler/application/site"
 )
 
+var (
+	errInvalidWorkers = errors.New("workers count should be positive")
+	errThrottled      = errors.New("server is throttling requests")
+)
+
 // Crawler represent web-crawler structure
 type Crawler struct {
-	Site      *site.Site     // web site for crawling
-	Duration  time.Duration  // total crawling duration
-	Semaphore chan int       // thread-blocking Semaphore channel
-	Verbose   bool           // verbose mode
-	wg        sync.WaitGroup // crawler WaitGroup
+	Site         *site.Site              // web site for crawling
+	Duration     time.Duration           // total crawling duration
+	Workers      int                     // number of concurrent crawling workers
+	Order        Order                   // pages crawling order
+	Verbose      bool                    // verbose mode
+	Client       *http.Client            // HTTP client for pages and robots.txt fetching
+	UserAgent    string                  // user-agent for robots.txt rules matching
+	IgnoreRobots bool                    // crawl pages regardless of robots.txt rules
+	Robots       *robots.Robots          // target site robots.txt rules
+	Rules        *rules.Rules            // include and exclude urls rules, nil - all site urls are crawled
+	MaxDepth     int                     // maximum crawling depth from entry page, 0 - unlimited
+	MaxPages     int                     // maximum number of fetched pages, 0 - unlimited
+	GracePeriod  time.Duration           // time to wait in progress pages after crawling cancellation
+	Checkpoint   string                  // crawling state file name, empty - no checkpoints
+	Interval     time.Duration           // interval between crawling state checkpoints
+	frontier     *frontier               // queue of pages waiting for crawling
+	fetched      int                     // number of started page fetches
+	deferred     []*site.Page            // pages not fetched because of interruption or pages limit
+	restored     []*site.Page            // crawling queue restored from checkpoint
+	RateLimit    float64                 // maximum requests per second to one host, 0 - unlimited
+	Delay        time.Duration           // minimum delay between requests to one host
+	HostWorkers  int                     // maximum concurrent requests to one host
+	Retries      int                     // maximum fetch attempts of page with transient errors
+	RetryBackoff time.Duration           // backoff before second fetch attempt, doubled on next ones
+	hosts        map[string]*hostLimiter // per-host politeness limiters
+	hostsRobots  map[string]*hostRobots  // robots.txt rules of site hosts other than entry page host
+	OnPage       func(page *site.Page)   // called from workers with every fetched page, nil - disabled
+	Progress     io.Writer               // output of crawling progress messages
+	mu           sync.Mutex              // mutex for fetch limit and hosts scheduling
 }
 
 // NewCrawler creates new Crawler structure instance
-func NewCrawler(targetURL *site.Url, verbose bool, semaphore chan int) (*Crawler, error) {
+func NewCrawler(targetURL *site.Url, verbose bool, workers int) (*Crawler, error) {
+	if workers < 1 {
+		return nil, errInvalidWorkers
+	}
 	crawler := &Crawler{
-		Site:      site.NewSite(targetURL),
-		Verbose:   verbose,
-		Semaphore: semaphore,
+		Site:        site.NewSite(targetURL),
+		Verbose:     verbose,
+		Client:      http.DefaultClient,
+		Workers:     workers,
+		HostWorkers: workers,
+		Retries:     1,
+		Progress:    os.Stdout,
+		hosts:       make(map[string]*hostLimiter),
+		hostsRobots: make(map[string]*hostRobots),
 	}
 	return crawler, nil
 }
 
-// StartCrawling starting crawling
-func (c *Crawler) StartCrawling() error {
+// StartCrawling starting crawling until all site pages are crawled
+// or given context is done. After context cancellation new pages are
+// not fetched and pages in progress are waited up to GracePeriod,
+// Site is marked as incomplete.
+func (c *Crawler) StartCrawling(ctx context.Context) error {
 	// print Crawler result after its execution
 	defer c.PrintResult()
 
 	// calculate total duration
 	defer c.calcDuration(time.Now())
 
-	fmt.Printf("Start crawling web site %s...\n", c.Site.Url.Host)
+	fmt.Fprintf(c.Progress, "Start crawling web site %s...\n", c.Site.Url.Host)
 
-	// add first waitgroup delta
-	c.wg.Add(1)
-	// took first semaphore slot
-	<-c.Semaphore
-	// start crawling site pages
-	if err := c.CrawlPage(c.Site.PageTree); err != nil && c.Verbose {
-		return err
+	// fetch target site robots.txt rules
+	c.initRobots(ctx)
+
+	// put entry page or restored queue to crawling queue
+	c.frontier = newFrontier(c.Order)
+	switch {
+	case c.restored != nil:
+		for _, page := range c.restored {
+			c.frontier.push(page)
+		}
+		c.restored = nil
+	default:
+		c.schedule(ctx, c.Site.PageTree)
 	}
 
+	// pages in progress are fetched with own context,
+	// which is canceled after grace period of crawling cancellation
+	fetchCtx, cancelFetch := context.WithCancel(context.Background())
+	defer cancelFetch()
+	go c.watchCancel(ctx, fetchCtx, cancelFetch)
+
 	// create "done: channel
 	done := make(chan struct{})
 
@@ -58,29 +120,232 @@ func (c *Crawler) StartCrawling() error {
 		go c.printTotal(done)
 	}
 
-	// waiting finish crawling of all site pages
-	c.wg.Wait()
+	// periodically save crawling state
+	checkpointsDone := make(chan struct{})
+	go c.checkpoints(done, checkpointsDone)
+
+	// start workers and wait until all site pages are crawled
+	var wg sync.WaitGroup
+	for i := 0; i < c.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.work(ctx, fetchCtx)
+		}()
+	}
+	wg.Wait()
+
 	close(done)
-	return nil
+	<-checkpointsDone
+
+	// mark pages left in queue after cancellation as not fetched
+	if ctx.Err() != nil {
+		c.Site.Incomplete = true
+		for _, page := range c.frontier.drain() {
+			c.deferPage(page)
+		}
+	}
+
+	// collect failed pages with pages linking to them
+	c.Site.FindBrokenLinks()
+
+	// save final crawling state, if there is something to resume
+	return c.finishCheckpoint()
+}
+
+// watchCancel stop crawling queue when crawling context is done
+// and cancel fetching context after grace period
+func (c *Crawler) watchCancel(ctx, fetchCtx context.Context, cancelFetch context.CancelFunc) {
+	select {
+	case <-fetchCtx.Done():
+		// crawling is finished
+		return
+	case <-ctx.Done():
+	}
+
+	c.frontier.close()
+
+	timer := time.NewTimer(c.GracePeriod)
+	defer timer.Stop()
+	select {
+	case <-fetchCtx.Done():
+	case <-timer.C:
+		cancelFetch()
+	}
 }
 
-// TODO need more decomposition
+// work crawl pages from frontier until it is exhausted or closed
+func (c *Crawler) work(ctx, fetchCtx context.Context) {
+	for {
+		page, ok := c.frontier.pop()
+		if !ok {
+			return
+		}
 
-// CrawlPage crawl given site page
-func (c *Crawler) CrawlPage(page *site.Page) error {
-	// defer page waitgroup counter
-	defer c.wg.Done()
+		// drain rest of frontier without fetching after pages limit is reached
+		if !c.reserveFetch() {
+			c.deferPage(page)
+			c.Site.LimitReached(site.LimitPages)
+			c.frontier.done()
+			continue
+		}
 
+		if err := c.visit(ctx, fetchCtx, page); err != nil && c.Verbose {
+			page.Logger.Error(err)
+		}
+
+		switch page.State {
+		case site.NotFetched:
+			// page canceled before or during fetching can be fetched after resume
+			c.releaseFetch()
+			c.deferPage(page)
+		case site.Linked:
+			// not html page is removed from site
+		default:
+			if c.OnPage != nil {
+				c.OnPage(page)
+			}
+		}
+		c.frontier.done()
+	}
+}
+
+// visit crawl given page respecting its host politeness restrictions.
+// Page is retried after backoff while server is throttling requests
+// and on transient errors up to Retries attempts,
+// attempts count and final error are recorded in page.
+func (c *Crawler) visit(ctx, fetchCtx context.Context, page *site.Page) error {
+	host := c.host(page.Url.Host)
+	var throttled, failed int
+	for {
+		release, err := host.acquire(ctx)
+		if err != nil {
+			page.State = site.NotFetched
+			return err
+		}
+		err = c.CrawlPage(fetchCtx, page)
+		release()
+
+		// canceled fetch is not an attempt, page is fetched again after resume
+		if page.State == site.NotFetched {
+			return err
+		}
+		page.Attempts++
+
+		switch {
+		case err == nil:
+			page.Error = ""
+			return nil
+		case err == errThrottled && throttled+1 < maxThrottleRetries:
+			throttled++
+		case isTransient(err) && failed+1 < c.Retries:
+			failed++
+			if !sleep(ctx, retryBackoff(c.RetryBackoff, failed)) {
+				page.State = site.NotFetched
+				return ctx.Err()
+			}
+		default:
+			page.Error = err.Error()
+			return err
+		}
+
+		if c.Verbose {
+			page.Logger.WithField("attempt", page.Attempts).Warning(err)
+		}
+	}
+}
+
+// host return politeness limiter of given host,
+// interval between requests is the longest of
+// delay, rate limit and robots.txt Crawl-delay
+func (c *Crawler) host(name string) *hostLimiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if limiter, ok := c.hosts[name]; ok {
+		return limiter
+	}
+
+	interval := c.Delay
+	if c.RateLimit > 0 {
+		if rateInterval := time.Duration(float64(time.Second) / c.RateLimit); rateInterval > interval {
+			interval = rateInterval
+		}
+	}
+	rules := c.Robots
+	if name != c.Site.Url.Host {
+		rules = nil
+		if r, ok := c.hostsRobots[name]; ok {
+			rules = r.rules
+		}
+	}
+	if rules != nil && rules.CrawlDelay > interval {
+		interval = rules.CrawlDelay
+	}
+
+	limiter := newHostLimiter(c.HostWorkers, interval)
+	c.hosts[name] = limiter
+	return limiter
+}
+
+// CrawlPage crawl given site page and put
+// its new child pages to crawling queue.
+// Page is either completely crawled or not changed at all.
+func (c *Crawler) CrawlPage(ctx context.Context, page *site.Page) error {
 	if c.Verbose {
 		page.Logger.Info("Start page crawling...")
 	}
 
-	// http request too new crawling page
-	resp, err := http.Get(page.Url.String())
+	// fetch whole page body before parsing
+	body, err := c.fetch(ctx, page)
 	if err != nil {
-		c.Semaphore <- 1
+		page.State = site.Failed
+		if ctx.Err() != nil {
+			page.State = site.NotFetched
+		}
 		return err
 	}
+
+	// page is not text/html
+	if body == nil {
+		return nil
+	}
+
+	// increase total site pages count
+	page.State = site.Crawled
+	c.Site.IncTotalPages()
+
+	c.parseLinks(ctx, page, bytes.NewReader(body))
+	return nil
+}
+
+// fetch request given page following redirects and read its body.
+// Fetching result is recorded in page Response.
+// Return nil body if page is not text/html or redirects to external host.
+func (c *Crawler) fetch(ctx context.Context, page *site.Page) ([]byte, error) {
+	start := time.Now()
+	response := &site.Response{FinalUrl: page.Url.String(), ContentLength: -1}
+	defer func() {
+		response.ResponseTime = time.Since(start).Milliseconds()
+	}()
+
+	// http request too new crawling page
+	page.Response = nil
+	resp, err := c.request(ctx, page.Url.URL, response)
+	if err != nil {
+		// keep redirects chain of failed or external redirect
+		if len(response.Redirects) > 0 {
+			last := response.Redirects[len(response.Redirects)-1]
+			response.Status = last.Status
+			response.FinalUrl = last.Location
+			page.Response = response
+		}
+		if err == errExternalRedirect {
+			page.State = site.Redirected
+			return nil, nil
+		}
+		return nil, err
+	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			if c.Verbose {
@@ -90,126 +355,343 @@ func (c *Crawler) CrawlPage(page *site.Page) error {
 		}
 	}()
 
-	// free semaphore slot
-	c.Semaphore <- 1
+	response.Status = resp.StatusCode
+	response.FinalUrl = resp.Request.URL.String()
+	response.ContentType = resp.Header.Get("Content-Type")
+	response.ContentLength = resp.ContentLength
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		response.LastModified = modified.UTC().Format(time.RFC3339)
+	}
+	page.Response = response
+
+	// slow down requests to host if server asks
+	host := c.host(page.Url.Host)
+	if isThrottled(resp.StatusCode) {
+		host.throttle(parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()))
+		return nil, errThrottled
+	}
+	host.success()
+
+	// client and server errors are broken pages, server errors are retried
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, &statusError{code: resp.StatusCode, status: resp.Status}
+	}
 
 	// TODO need to find better way for check page format
 	// check response format, need only tex/html for next crawling
-	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/html") {
-		// if page is not text/html - delete it from site Hash Map
+	if contentType := response.ContentType; !strings.HasPrefix(contentType, "text/html") {
+		// if page is not text/html - delete it from site Hash Map and leave only as a link
 		c.Site.DeletePageFromSite(page.Url.String())
-		return fmt.Errorf("unsupported page format - %s", contentType)
+		page.State = site.Linked
+		if c.Verbose {
+			page.Logger.Warningf("unsupported page format - %s", contentType)
+		}
+		return nil, nil
 	}
 
-	// increase total site pages count
-	c.Site.TotalPages++
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	response.ContentLength = int64(len(body))
+	return body, nil
+}
 
+// parseLinks parse html page body, add valid links
+// as child pages and put new ones to crawling queue
+func (c *Crawler) parseLinks(ctx context.Context, page *site.Page, body io.Reader) {
 	// parse html body
-	tokens := html.NewTokenizer(resp.Body)
+	tokens := html.NewTokenizer(body)
+
+	// href and anchor text of current <a> tag
+	var (
+		link   string
+		text   strings.Builder
+		inLink bool
+	)
 
 	// find valid html tags
 	for {
 		switch tokens.Next() {
 		case html.ErrorToken:
-			return nil
-		case html.StartTagToken:
-			// we need only <a> html tag
-			token := tokens.Token()
-			if token.Data != "a" {
-				continue
-			}
-
-			// get link from href attribute
-			link, ok := getLink(token)
-			if !ok {
-				continue
+			// unclosed <a> tag at the end of page
+			if inLink {
+				c.addLink(ctx, page, link, text.String())
 			}
+			return
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokens.Token()
+			switch token.Data {
+			case "a":
+				// previous <a> tag is not closed
+				if inLink {
+					c.addLink(ctx, page, link, text.String())
+				}
 
-			// validate and add child page to parent page
-			childPage, err := page.AddSubPage(link)
-			if err != nil {
-				// TODO need to implement logging levels
-				if c.Verbose {
-					page.Logger.WithField("link", link).Error(err)
+				// get link from href attribute
+				link, inLink = getLink(token)
+				text.Reset()
+			case "img":
+				// image alt is anchor text of image link
+				if inLink {
+					text.WriteString(" " + getAttr(token, "alt") + " ")
 				}
-				continue
 			}
+		case html.TextToken:
+			if inLink {
+				text.Write(tokens.Text())
+			}
+		case html.EndTagToken:
+			if name, _ := tokens.TagName(); inLink && string(name) == "a" {
+				c.addLink(ctx, page, link, text.String())
+				inLink = false
+			}
+		}
+	}
+}
 
-			// add child page to parent links slice
-			c.Site.AddPageToParent(childPage.Url.String(), page.Url.String())
+// addLink validate and add given link with anchor text as
+// child page of given page and put new page to crawling queue
+func (c *Crawler) addLink(ctx context.Context, page *site.Page, link, text string) {
+	// validate and add child page to parent page
+	childPage, err := page.AddSubPage(link)
+	if err != nil {
+		// TODO need to implement logging levels
+		if c.Verbose {
+			page.Logger.WithField("link", link).Error(err)
+		}
+		return
+	}
 
-			// validate and add page to site
-			if err := c.Site.AddPageToSite(childPage.Url.String()); err != nil {
-				// TODO need to implement logging levels
-				if c.Verbose {
-					childPage.Logger.Error(err)
-				}
-				continue
-			}
+	// remember source page of the link for broken links report
+	c.Site.AddSource(childPage.Url.String(), site.Source{
+		Url:  page.Url.String(),
+		Text: strings.Join(strings.Fields(text), " "),
+	})
 
-		CrawlChild:
-			// check if Crawler have available threads
-			select {
-			case <-c.Semaphore:
-				// start crawl child page if we have
-				c.wg.Add(1)
-				go func() {
-					if err := c.CrawlPage(childPage); err != nil && c.Verbose {
-						childPage.Logger.Error(err)
-					}
-				}()
-			default:
-				// print warning and try again after one second
-				if c.Verbose {
-					childPage.Logger.Warning("Threads limit reached... WAIT")
-				}
-				time.Sleep(10 * time.Millisecond)
-				goto CrawlChild
-			}
+	// validate and add page to site, links to
+	// excluded and skipped pages get their state
+	if err := c.Site.AddPageToSite(childPage); err != nil {
+		childPage.State = c.Site.LinkState(childPage.Url.String())
+		// TODO need to implement logging levels
+		if c.Verbose {
+			childPage.Logger.Error(err)
 		}
+		return
 	}
+
+	// put child page to crawling queue
+	c.schedule(ctx, childPage)
+}
+
+// schedule put given page to crawling queue if crawl rules,
+// robots.txt and crawl depth limit allow its crawling
+func (c *Crawler) schedule(ctx context.Context, page *site.Page) {
+	// check if crawl rules include page
+	if !c.included(page) {
+		return
+	}
+
+	// check if robots.txt allows page crawling
+	if !c.allowed(ctx, page) {
+		return
+	}
+
+	// page deeper than depth limit is kept in site, but not fetched
+	if c.MaxDepth > 0 && page.Depth > c.MaxDepth {
+		page.State = site.NotFetched
+		c.Site.LimitReached(site.LimitDepth)
+		return
+	}
+
+	page.State = site.Queued
+	c.frontier.push(page)
+}
+
+// releaseFetch return reserved page fetch back
+func (c *Crawler) releaseFetch() {
+	c.mu.Lock()
+	c.fetched--
+	c.mu.Unlock()
+}
+
+// deferPage mark given page as not fetched and
+// keep it for crawling queue of resumed crawling
+func (c *Crawler) deferPage(page *site.Page) {
+	page.State = site.NotFetched
+	c.mu.Lock()
+	c.deferred = append(c.deferred, page)
+	c.mu.Unlock()
+}
+
+// reserveFetch reserve one page fetch,
+// return false if pages limit is reached
+func (c *Crawler) reserveFetch() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.MaxPages > 0 && c.fetched >= c.MaxPages {
+		return false
+	}
+	c.fetched++
+	return true
+}
+
+// initRobots fetch and parse target site robots.txt
+func (c *Crawler) initRobots(ctx context.Context) {
+	if c.IgnoreRobots {
+		return
+	}
+	c.Robots = c.fetchRobots(ctx, c.Site.Url.URL)
+}
+
+// fetchRobots fetch and parse robots.txt of given url host, fetching is
+// retried on errors up to Retries attempts. Unavailable robots.txt
+// allows crawling of the whole host.
+func (c *Crawler) fetchRobots(ctx context.Context, target *url.URL) *robots.Robots {
+	for attempt := 1; ; attempt++ {
+		rules, err := robots.Fetch(ctx, c.Client, target, c.UserAgent)
+		if err == nil {
+			return rules
+		}
+		if attempt < c.Retries && sleep(ctx, retryBackoff(c.RetryBackoff, attempt)) {
+			continue
+		}
+
+		// crawling is interrupted, pages are not fetched anyway
+		if ctx.Err() == nil {
+			c.Site.PageTree.Logger.WithField("robots", target.Host+"/robots.txt").
+				Warningf("robots.txt is unavailable, all pages of the host are allowed: %v", err)
+		}
+		return robots.AllowAll()
+	}
+}
+
+// hostRobots represent robots.txt rules of site host fetched once
+type hostRobots struct {
+	once  sync.Once
+	rules *robots.Robots
+}
+
+// hostRules return robots.txt rules of given page host, rules of site
+// hosts other than entry page host are fetched on first use with given context
+func (c *Crawler) hostRules(ctx context.Context, page *site.Page) *robots.Robots {
+	if c.IgnoreRobots || page.Url.Host == c.Site.Url.Host {
+		return c.Robots
+	}
+
+	c.mu.Lock()
+	host, ok := c.hostsRobots[page.Url.Host]
+	if !ok {
+		host = &hostRobots{}
+		c.hostsRobots[page.Url.Host] = host
+	}
+	c.mu.Unlock()
+
+	host.once.Do(func() {
+		rules := c.fetchRobots(ctx, page.Url.URL)
+		c.mu.Lock()
+		host.rules = rules
+		c.mu.Unlock()
+	})
+	return host.rules
+}
+
+// allowed check if robots.txt allows given page crawling, disallowed
+// page gets skipped state and is marked as skipped in Site
+func (c *Crawler) allowed(ctx context.Context, page *site.Page) bool {
+	rules := c.hostRules(ctx, page)
+	if rules == nil {
+		return true
+	}
+
+	ok, rule := rules.Allowed(page.Url.URL)
+	if ok {
+		return true
+	}
+
+	reason := fmt.Sprintf("disallowed by robots.txt rule %q", rule)
+	if c.Verbose {
+		page.Logger.Warning(reason)
+	}
+	page.State = site.Skipped
+	c.Site.SkipPage(page.Url.String(), reason)
+	return false
+}
+
+// included check if crawl rules include given page, excluded page
+// gets excluded state and is marked as excluded in Site with matched rule.
+// Entry page is always included.
+func (c *Crawler) included(page *site.Page) bool {
+	if page == c.Site.PageTree {
+		return true
+	}
+
+	ok, rule := c.Rules.Allowed(page.Url.URL)
+	if ok {
+		return true
+	}
+
+	reason := "not matched by include rules"
+	if rule != nil {
+		reason = rule.String()
+	}
+	if c.Verbose {
+		page.Logger.Warningf("excluded by crawl rules: %s", reason)
+	}
+	page.State = site.Excluded
+	c.Site.ExcludePage(page.Url.String(), reason)
+	return false
 }
 
 // duration calculate total Crawler execution time
 func (c *Crawler) calcDuration(invocation time.Time) {
 	c.Duration = time.Since(invocation)
+	c.Site.Duration = c.Duration
 }
 
 // printTotal concurrently print total crawled site pages
 func (c *Crawler) printTotal(done chan struct{}) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-done:
 			goto Finish
-		default:
-			fmt.Printf("\rTotal pages: %d...", c.Site.TotalPages)
+		case <-ticker.C:
+			fmt.Fprintf(c.Progress, "\rTotal pages: %d...", c.Site.GetTotalPages())
 		}
 	}
 Finish:
-	fmt.Println("\nAll done!")
+	fmt.Fprintf(c.Progress, "\rTotal pages: %d...", c.Site.GetTotalPages())
+	fmt.Fprintln(c.Progress, "\nAll done!")
 }
 
 // PrintResult print Crawler results
 func (c *Crawler) PrintResult() {
-	fmt.Printf("%d pages crawled at %s in %s\n", c.Site.TotalPages, c.Site.Url.Host, c.Duration)
-}
-
-// removeAnchor remove anchor from given string link
-func removeAnchor(s string) string {
-	if idx := strings.Index(s, "/#"); idx != -1 {
-		return s[:idx]
+	fmt.Fprintf(c.Progress, "%d pages crawled at %s in %s\n", c.Site.TotalPages, c.Site.Url.Host, c.Duration)
+	if c.Site.Incomplete {
+		fmt.Fprintln(c.Progress, "Crawling was interrupted, sitemap is incomplete")
 	}
-	return s
 }
 
 // getLink get href Link from attribute of given html tag
 func getLink(token html.Token) (link string, ok bool) {
 	for _, attr := range token.Attr {
-		// finds"href" attribute and remove anchor
+		// finds"href" attribute, anchor is removed by url normalization
 		if attr.Key == "href" {
-			link = removeAnchor(attr.Val)
+			link = attr.Val
 			ok = true
 		}
 	}
 	return
 }
+
+// getAttr return value of given html token attribute
+func getAttr(token html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
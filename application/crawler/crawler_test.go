@@ -1,13 +1,27 @@
 package crawler
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/andskur/web-crawler/application/rules"
 	"github.com/andskur/web-crawler/application/site"
 )
 
 func TestCrawler_CrawlPage(t *testing.T) {
-	c, _ := NewCrawler(getTestSite().Url, false, make(chan int, 10000))
+	server := getTestServer()
+	defer server.Close()
+
+	c, _ := NewCrawler(getTestSite(server.URL).Url, false, 10)
+	c.frontier = newFrontier(BFS)
 	type args struct {
 		page *site.Page
 	}
@@ -20,37 +34,561 @@ func TestCrawler_CrawlPage(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c.wg.Add(1)
-			if err := c.CrawlPage(tt.args.page); (err != nil) != tt.wantErr {
+			if err := c.CrawlPage(context.Background(), tt.args.page); (err != nil) != tt.wantErr {
 				t.Errorf("Crawler.CrawlPage() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			// about, blog and admin child pages should be queued
+			if len(c.frontier.pages) != 3 {
+				t.Errorf("Crawler.CrawlPage() queued pages = %v, want %v", len(c.frontier.pages), 3)
+			}
 		})
 	}
 }
 
-func Test_removeAnchor(t *testing.T) {
-	type args struct {
-		s string
+func TestCrawler_StartCrawling(t *testing.T) {
+	server := getTestServer()
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		ignoreRobots bool
+		wantPages    int
+		wantSkipped  []string
+	}{
+		{"honorRobots", false, 4, []string{server.URL + "/admin"}},
+		{"ignoreRobots", true, 5, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := NewCrawler(getTestSite(server.URL).Url, true, 2)
+			c.UserAgent = "web-crawler"
+			c.IgnoreRobots = tt.ignoreRobots
+			if err := c.StartCrawling(context.Background()); err != nil {
+				t.Errorf("Crawler.StartCrawling() error = %v", err)
+				return
+			}
+			if c.Site.TotalPages != tt.wantPages {
+				t.Errorf("Crawler.StartCrawling() total pages = %v, want %v", c.Site.TotalPages, tt.wantPages)
+			}
+			if len(c.Site.Skipped) != len(tt.wantSkipped) {
+				t.Errorf("Crawler.StartCrawling() skipped = %v, want %v", c.Site.Skipped, tt.wantSkipped)
+			}
+			for _, skipped := range tt.wantSkipped {
+				if _, ok := c.Site.Skipped[skipped]; !ok {
+					t.Errorf("Crawler.StartCrawling() page %s is not skipped", skipped)
+				}
+			}
+		})
+	}
+}
+
+func TestCrawler_limits(t *testing.T) {
+	server := getTestServer()
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		maxDepth       int
+		maxPages       int
+		wantPages      int
+		wantLimits     site.Limits
+		wantNotFetched []string
+	}{
+		{"unlimited", 0, 0, 4, nil, nil},
+		{"maxDepth", 1, 0, 3, []string{site.LimitDepth}, []string{"/blog/post"}},
+		{"maxPages", 0, 3, 3, []string{site.LimitPages}, []string{"/blog/post"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := NewCrawler(getTestSite(server.URL).Url, true, 1)
+			c.MaxDepth = tt.maxDepth
+			c.MaxPages = tt.maxPages
+			if err := c.StartCrawling(context.Background()); err != nil {
+				t.Errorf("Crawler.StartCrawling() error = %v", err)
+				return
+			}
+			if c.Site.TotalPages != tt.wantPages {
+				t.Errorf("Crawler.StartCrawling() total pages = %v, want %v", c.Site.TotalPages, tt.wantPages)
+			}
+			if !reflect.DeepEqual(c.Site.Limits, tt.wantLimits) {
+				t.Errorf("Crawler.StartCrawling() limits = %v, want %v", c.Site.Limits, tt.wantLimits)
+			}
+			for _, path := range tt.wantNotFetched {
+				page, ok := c.Site.HashMap[server.URL+path]
+				if !ok {
+					t.Errorf("Crawler.StartCrawling() page %s is not found", path)
+					continue
+				}
+				if page.State != site.NotFetched {
+					t.Errorf("Crawler.StartCrawling() page %s state = %v, want %v", path, page.State, site.NotFetched)
+				}
+			}
+		})
+	}
+}
+
+func TestCrawler_rules(t *testing.T) {
+	server := getTestServer()
+	defer server.Close()
+
+	crawlRules, err := rules.Parse(strings.NewReader("include /blog/\nexclude /blog/post"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := NewCrawler(getTestSite(server.URL).Url, false, 2)
+	c.Rules = crawlRules
+	if err := c.StartCrawling(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Site.TotalPages != 2 {
+		t.Errorf("Crawler.StartCrawling() total pages = %v, want 2", c.Site.TotalPages)
+	}
+	want := site.SkippedPages{
+		server.URL + "/about":     "not matched by include rules",
+		server.URL + "/admin":     "not matched by include rules",
+		server.URL + "/blog/post": "exclude /blog/post",
+	}
+	if !reflect.DeepEqual(c.Site.Excluded, want) {
+		t.Errorf("Crawler.StartCrawling() excluded = %v, want %v", c.Site.Excluded, want)
+	}
+	walkTree(c.Site.PageTree, func(page *site.Page) {
+		if _, ok := want[page.Url.String()]; ok && page.State != site.Excluded {
+			t.Errorf("Crawler.StartCrawling() excluded page %s state = %v, want %v", page.Url, page.State, site.Excluded)
+		}
+	})
+	if len(c.Site.Skipped) != 0 {
+		t.Errorf("Crawler.StartCrawling() skipped = %v, want none", c.Site.Skipped)
+	}
+}
+
+func TestCrawler_scope(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+		case "/", "/private":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<a href="/private">Private</a>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer other.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprintf(w, `<a href="%s/">Other</a><a href="/moved">Moved</a>`, other.URL)
+		case "/moved":
+			http.Redirect(w, r, other.URL+"/", http.StatusMovedPermanently)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	otherUrl, _ := site.ParseRequestURI(other.URL)
+
+	tests := []struct {
+		name        string
+		scope       *site.Scope
+		wantPages   int
+		wantSkipped []string
+		wantState   site.PageState
+	}{
+		{"host", nil, 1, nil, site.Redirected},
+		{"hosts", &site.Scope{Mode: site.ScopeHosts, Hosts: []string{otherUrl.Host}}, 3, []string{other.URL + "/private"}, site.Crawled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := NewCrawler(getTestSite(server.URL).Url, false, 2)
+			c.Site.SetScope(tt.scope)
+			if err := c.StartCrawling(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if c.Site.TotalPages != tt.wantPages {
+				t.Errorf("Crawler.StartCrawling() total pages = %v, want %v", c.Site.TotalPages, tt.wantPages)
+			}
+			var skipped []string
+			for url := range c.Site.Skipped {
+				skipped = append(skipped, url)
+			}
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("Crawler.StartCrawling() skipped = %v, want %v", skipped, tt.wantSkipped)
+			}
+			if moved := c.Site.HashMap[server.URL+"/moved"]; moved == nil || moved.State != tt.wantState {
+				t.Errorf("Crawler.StartCrawling() redirected page = %v, want state %v", moved, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestCrawler_hostRules(t *testing.T) {
+	// robots.txt of other host responds only after request cancellation
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer other.Close()
+
+	c, _ := NewCrawler(getTestSite("http://monzo.com").Url, false, 2)
+	c.Site.SetScope(&site.Scope{Mode: site.ScopeHosts, Hosts: []string{strings.TrimPrefix(other.URL, "http://")}})
+	page, err := c.Site.PageTree.AddSubPage(other.URL + "/about")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan bool)
+	go func() {
+		done <- c.allowed(ctx, page)
+	}()
+	select {
+	case allowed := <-done:
+		if !allowed {
+			t.Errorf("Crawler.allowed() = %v, want %v", allowed, true)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Crawler.allowed() robots.txt is fetched without crawling context")
+	}
+	if _, ok := c.Site.Skipped[page.Url.String()]; ok {
+		t.Errorf("Crawler.allowed() page is skipped, want allowed")
+	}
+}
+
+func TestCrawler_allowed(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		down      bool
+		want      bool
+		wantState site.PageState
+	}{
+		{"disallowed", http.StatusOK, false, false, site.Skipped},
+		{"notFound", http.StatusNotFound, false, true, site.Linked},
+		{"unauthorized", http.StatusUnauthorized, false, false, site.Skipped},
+		{"forbidden", http.StatusForbidden, false, false, site.Skipped},
+		{"serverError", http.StatusServiceUnavailable, false, true, site.Linked},
+		{"networkError", http.StatusOK, true, true, site.Linked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+			}))
+			if tt.down {
+				server.Close()
+			}
+			defer server.Close()
+
+			c, _ := NewCrawler(getTestSite(server.URL).Url, false, 1)
+			c.Retries = 2
+			c.initRobots(context.Background())
+			page, _ := c.Site.PageTree.AddSubPage("/private")
+
+			if got := c.allowed(context.Background(), page); got != tt.want {
+				t.Errorf("Crawler.allowed() = %v, want %v", got, tt.want)
+			}
+			if page.State != tt.wantState {
+				t.Errorf("Crawler.allowed() page state = %v, want %v", page.State, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestCrawler_cancel(t *testing.T) {
+	// every page links to two more pages and responds slowly
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/html")
+		path := strings.TrimSuffix(r.URL.Path, "/")
+		fmt.Fprintf(w, `<a href="%s/a">A</a><a href="%s/b">B</a>`, path, path)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		gracePeriod time.Duration
+		wantPages   bool
+	}{
+		{"waitInProgress", time.Second, true},
+		{"cancelInProgress", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := NewCrawler(getTestSite(server.URL).Url, true, 2)
+			c.GracePeriod = tt.gracePeriod
+
+			ctx, cancel := context.WithTimeout(context.Background(), 120*time.Millisecond)
+			defer cancel()
+
+			if err := c.StartCrawling(ctx); err != nil {
+				t.Errorf("Crawler.StartCrawling() error = %v", err)
+				return
+			}
+			if !c.Site.Incomplete {
+				t.Errorf("Crawler.StartCrawling() site is not marked as incomplete")
+			}
+			if (c.Site.TotalPages > 3) != tt.wantPages {
+				t.Errorf("Crawler.StartCrawling() total pages = %v, want in progress pages crawled %v", c.Site.TotalPages, tt.wantPages)
+			}
+			for url, page := range c.Site.HashMap {
+				if page.State == site.Queued {
+					t.Errorf("Crawler.StartCrawling() page %s left in queued state", url)
+				}
+			}
+		})
 	}
+}
+
+func TestNewCrawler(t *testing.T) {
 	tests := []struct {
-		name string
-		args args
-		want string
+		name    string
+		workers int
+		wantErr bool
 	}{
-		{"anchorFullLink", args{"https://monzo.com/blog/#gotobotton"}, "https://monzo.com/blog"},
-		{"anchorPath", args{"/blog/#gotobotton"}, "/blog"},
+		{"valid", 10, false},
+		{"zeroWorkers", 0, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := removeAnchor(tt.args.s); got != tt.want {
-				t.Errorf("removeAnchor() = %v, want %v", got, tt.want)
+			_, err := NewCrawler(getTestSite("https://monzo.com").Url, false, tt.workers)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewCrawler() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
-func getTestSite() *site.Site {
-	url, _ := site.ParseRequestURI("https://ya.ru")
+func TestCrawler_throttled(t *testing.T) {
+	// first request of every page is throttled
+	var mu sync.Mutex
+	requested := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		first := !requested[r.URL.Path]
+		requested[r.URL.Path] = true
+		mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/robots.txt":
+			http.NotFound(w, r)
+		case first:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<a href="/about">About</a>`)
+		}
+	}))
+	defer server.Close()
+
+	c, _ := NewCrawler(getTestSite(server.URL).Url, true, 2)
+	start := time.Now()
+	if err := c.StartCrawling(context.Background()); err != nil {
+		t.Errorf("Crawler.StartCrawling() error = %v", err)
+		return
+	}
+	if c.Site.TotalPages != 2 {
+		t.Errorf("Crawler.StartCrawling() total pages = %v, want %v", c.Site.TotalPages, 2)
+	}
+	if elapsed := time.Since(start); elapsed < 2*time.Second {
+		t.Errorf("Crawler.StartCrawling() Retry-After is not respected, crawling took %s", elapsed)
+	}
+}
+
+func TestCrawler_onPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt", "/missing":
+			http.NotFound(w, r)
+		case "/file.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<a href="/about">About</a><a href="/missing">Missing</a><a href="/file.pdf">File</a>`)
+		}
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var got []string
+	c, _ := NewCrawler(getTestSite(server.URL).Url, true, 2)
+	c.OnPage = func(page *site.Page) {
+		mu.Lock()
+		got = append(got, page.Url.String()+" "+page.State.String())
+		mu.Unlock()
+	}
+	if err := c.StartCrawling(context.Background()); err != nil {
+		t.Errorf("Crawler.StartCrawling() error = %v", err)
+		return
+	}
+
+	sort.Strings(got)
+	want := []string{server.URL + " crawled", server.URL + "/about crawled", server.URL + "/missing failed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Crawler.OnPage() pages = %v, want %v", got, want)
+	}
+}
+
+func TestCrawler_retries(t *testing.T) {
+	// "/flaky" fails twice, "/dead" always fails
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		count := requests[r.URL.Path]
+		mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/robots.txt":
+			http.NotFound(w, r)
+		case r.URL.Path == "/dead", r.URL.Path == "/flaky" && count <= 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<a href="/flaky">Flaky</a><a href="/dead">Dead</a>`)
+		}
+	}))
+	defer server.Close()
+
+	c, _ := NewCrawler(getTestSite(server.URL).Url, true, 2)
+	c.Retries = 3
+	c.RetryBackoff = time.Millisecond
+	if err := c.StartCrawling(context.Background()); err != nil {
+		t.Errorf("Crawler.StartCrawling() error = %v", err)
+		return
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		wantState    site.PageState
+		wantAttempts int
+		wantError    string
+	}{
+		{"entry", "", site.Crawled, 1, ""},
+		{"flaky", "/flaky", site.Crawled, 3, ""},
+		{"dead", "/dead", site.Failed, 3, "server responded 502 Bad Gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, ok := c.Site.HashMap[server.URL+tt.path]
+			if !ok {
+				t.Errorf("Crawler.StartCrawling() page %s is not in site", tt.path)
+				return
+			}
+			if page.State != tt.wantState {
+				t.Errorf("Crawler.StartCrawling() page state = %v, want %v", page.State, tt.wantState)
+			}
+			if page.Attempts != tt.wantAttempts {
+				t.Errorf("Crawler.StartCrawling() page attempts = %v, want %v", page.Attempts, tt.wantAttempts)
+			}
+			if page.Error != tt.wantError {
+				t.Errorf("Crawler.StartCrawling() page error = %v, want %v", page.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestCrawler_brokenLinks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<a href="/missing">Missing <b>page</b></a><a href="/about">About`)
+		case "/about":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<a href="/missing/"><img src="/logo.png" alt="Gone"></a><a href="/error">Error</a>`)
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<a href="/not-parsed">Not found</a>`)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, _ := NewCrawler(getTestSite(server.URL).Url, true, 2)
+	if err := c.StartCrawling(context.Background()); err != nil {
+		t.Errorf("Crawler.StartCrawling() error = %v", err)
+		return
+	}
+
+	// not found pages are not crawled
+	if c.Site.TotalPages != 2 {
+		t.Errorf("Crawler.StartCrawling() total pages = %v, want %v", c.Site.TotalPages, 2)
+	}
+
+	want := site.BrokenLinks{
+		{
+			Url:     server.URL + "/error",
+			Status:  http.StatusInternalServerError,
+			Error:   "server responded 500 Internal Server Error",
+			Sources: []site.Source{{Url: server.URL + "/about", Text: "Error"}},
+		},
+		{
+			Url:    server.URL + "/missing",
+			Status: http.StatusNotFound,
+			Error:  "server responded 404 Not Found",
+			Sources: []site.Source{
+				{Url: server.URL, Text: "Missing page"},
+				{Url: server.URL + "/about", Text: "Gone"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(c.Site.Broken, want) {
+		t.Errorf("Crawler.StartCrawling() broken links = %v, want %v", c.Site.Broken, want)
+	}
+}
+
+// walkTree call given function for given tree page and all its child pages
+func walkTree(page *site.Page, fn func(page *site.Page)) {
+	fn(page)
+	for _, link := range page.Links {
+		walkTree(link, fn)
+	}
+}
+
+func getTestSite(target string) *site.Site {
+	url, _ := site.ParseRequestURI(target)
 	site := site.NewSite(url)
 	return site
 }
+
+// getTestServer create test web site with robots.txt
+func getTestServer() *httptest.Server {
+	pages := map[string]string{
+		"/":           `<a href="/about">About</a><a href="/blog/">Blog</a><a href="/admin">Admin</a><a href="https://twitter.com">Twitter</a>`,
+		"/about":      `<a href="/">Home</a><a href="/blog/">Blog</a>`,
+		"/blog/":      `<a href="/about">About</a><a href="/blog/#comments">Comments</a><a href="/blog/post">Post</a>`,
+		"/blog/post":  `<a href="/">Home</a>`,
+		"/admin":      `<a href="/">Home</a>`,
+		"/robots.txt": "User-agent: *\nDisallow: /admin\n",
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Path == "/robots.txt" {
+			w.Header().Set("Content-Type", "text/plain")
+		} else {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		fmt.Fprint(w, body)
+	}))
+}
@@ -0,0 +1,158 @@
+package crawler
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+// Order is Enum that represent
+// order of pages crawling
+type Order int
+
+// available crawl Order constants
+const (
+	BFS Order = iota // breadth-first, FIFO frontier
+	DFS              // depth-first, LIFO frontier
+	unsupportedOrder
+)
+
+// orders is slice of crawl order string representations
+var orders = [...]string{
+	BFS: "bfs",
+	DFS: "dfs",
+}
+
+// String return crawl order enum as a string
+func (o Order) String() string {
+	return orders[o]
+}
+
+// ParseOrder return new Order enum from given string
+func ParseOrder(s string) (Order, error) {
+	for i, r := range orders {
+		if s == r {
+			return Order(i), nil
+		}
+	}
+	return unsupportedOrder, fmt.Errorf("invalid crawl Order value %q", s)
+}
+
+// frontier represent queue of pages waiting for crawling.
+// Crawling is finished when frontier is empty
+// and no page is in progress.
+type frontier struct {
+	order   Order        // pages pop order
+	pages   []*site.Page // queued pages
+	pending int          // queued and in progress pages count
+	closed  bool         // frontier is closed for popping
+	paused  bool         // popping is paused until resume
+	mu      sync.Mutex   // mutex for threadsafe queue operations
+	cond    *sync.Cond   // condition for waiting new pages
+}
+
+// newFrontier create new empty frontier with given pop order
+func newFrontier(order Order) *frontier {
+	f := &frontier{order: order}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// push add page to frontier, never blocks
+func (f *frontier) push(page *site.Page) {
+	f.mu.Lock()
+	f.pages = append(f.pages, page)
+	f.pending++
+	f.mu.Unlock()
+	f.cond.Signal()
+}
+
+// pop take next page from frontier, blocks until page is available.
+// Return false when frontier is closed or when it is empty
+// and no page is in progress.
+func (f *frontier) pop() (*site.Page, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for (len(f.pages) == 0 && f.pending > 0 || f.paused) && !f.closed {
+		f.cond.Wait()
+	}
+	if len(f.pages) == 0 || f.closed {
+		return nil, false
+	}
+
+	var page *site.Page
+	switch f.order {
+	case DFS:
+		page = f.pages[len(f.pages)-1]
+		f.pages[len(f.pages)-1] = nil
+		f.pages = f.pages[:len(f.pages)-1]
+	default:
+		page = f.pages[0]
+		f.pages[0] = nil
+		f.pages = f.pages[1:]
+	}
+	return page, true
+}
+
+// done mark popped page as finished
+func (f *frontier) done() {
+	f.mu.Lock()
+	f.pending--
+	wakeUp := f.pending == 0 || f.paused
+	f.mu.Unlock()
+
+	// wake up all waiting workers to finish
+	// or pause waiting for pages in progress
+	if wakeUp {
+		f.cond.Broadcast()
+	}
+}
+
+// pause stop popping pages from frontier
+// and wait until all pages in progress are done
+func (f *frontier) pause() {
+	f.mu.Lock()
+	f.paused = true
+	for f.pending > len(f.pages) {
+		f.cond.Wait()
+	}
+	f.mu.Unlock()
+}
+
+// resume continue popping pages after pause
+func (f *frontier) resume() {
+	f.mu.Lock()
+	f.paused = false
+	f.mu.Unlock()
+	f.cond.Broadcast()
+}
+
+// queued return copy of queued pages slice
+func (f *frontier) queued() []*site.Page {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pages := make([]*site.Page, len(f.pages))
+	copy(pages, f.pages)
+	return pages
+}
+
+// close stop popping pages from frontier,
+// all waiting workers are released
+func (f *frontier) close() {
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+	f.cond.Broadcast()
+}
+
+// drain remove and return all queued pages
+func (f *frontier) drain() []*site.Page {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pages := f.pages
+	f.pending -= len(pages)
+	f.pages = nil
+	return pages
+}
@@ -0,0 +1,144 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+func TestParseOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Order
+		wantErr bool
+	}{
+		{"bfs", "bfs", BFS, false},
+		{"dfs", "dfs", DFS, false},
+		{"invalid", "random", unsupportedOrder, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOrder(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseOrder() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_frontier_pop(t *testing.T) {
+	tests := []struct {
+		name  string
+		order Order
+		want  []string
+	}{
+		{"bfs", BFS, []string{"/a", "/b", "/c"}},
+		{"dfs", DFS, []string{"/c", "/b", "/a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFrontier(tt.order)
+			for _, path := range []string{"/a", "/b", "/c"} {
+				f.push(getTestPage(path))
+			}
+
+			for _, want := range tt.want {
+				page, ok := f.pop()
+				if !ok {
+					t.Errorf("frontier.pop() frontier exhausted, want %v", want)
+					return
+				}
+				if page.Url.Path != want {
+					t.Errorf("frontier.pop() = %v, want %v", page.Url.Path, want)
+				}
+				f.done()
+			}
+
+			if _, ok := f.pop(); ok {
+				t.Errorf("frontier.pop() frontier is not exhausted")
+			}
+		})
+	}
+}
+
+func Test_frontier_waitInProgress(t *testing.T) {
+	f := newFrontier(BFS)
+	f.push(getTestPage("/a"))
+	f.pop()
+
+	// second worker should wait until page in progress add new pages
+	popped := make(chan string)
+	go func() {
+		page, ok := f.pop()
+		if !ok {
+			popped <- ""
+			return
+		}
+		popped <- page.Url.Path
+	}()
+
+	f.push(getTestPage("/b"))
+	f.done()
+
+	if got := <-popped; got != "/b" {
+		t.Errorf("frontier.pop() = %v, want %v", got, "/b")
+	}
+}
+
+func Test_frontier_close(t *testing.T) {
+	f := newFrontier(BFS)
+	f.push(getTestPage("/a"))
+	f.pop()
+	f.push(getTestPage("/b"))
+	f.push(getTestPage("/c"))
+
+	f.close()
+	if _, ok := f.pop(); ok {
+		t.Errorf("frontier.pop() page popped from closed frontier")
+	}
+
+	if drained := f.drain(); len(drained) != 2 {
+		t.Errorf("frontier.drain() = %v pages, want %v", len(drained), 2)
+	}
+	f.done()
+	if f.pending != 0 {
+		t.Errorf("frontier.drain() pending = %v, want %v", f.pending, 0)
+	}
+}
+
+func Test_frontier_pause(t *testing.T) {
+	f := newFrontier(BFS)
+	f.push(getTestPage("/a"))
+	f.push(getTestPage("/b"))
+	f.pop()
+
+	// pause should wait until page in progress is done
+	paused := make(chan struct{})
+	go func() {
+		f.pause()
+		close(paused)
+	}()
+
+	f.push(getTestPage("/c"))
+	f.done()
+	<-paused
+
+	if queued := f.queued(); len(queued) != 2 {
+		t.Errorf("frontier.queued() = %v pages, want %v", len(queued), 2)
+	}
+
+	f.resume()
+	if page, ok := f.pop(); !ok || page.Url.Path != "/b" {
+		t.Errorf("frontier.pop() after resume = %v, want %v", page, "/b")
+	}
+}
+
+func getTestPage(path string) *site.Page {
+	url, _ := site.ParseRequestURI("https://monzo.com" + path)
+	return site.NewPage(url)
+}
@@ -0,0 +1,119 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Backoff parameters for throttling server responses
+const (
+	minBackoff         = time.Second     // first backoff without Retry-After header
+	maxBackoff         = 5 * time.Minute // maximum waiting time before next request
+	maxThrottleRetries = 5               // maximum attempts of throttled page
+)
+
+// hostLimiter represent per-host politeness restrictions:
+// concurrent requests cap, minimum interval between requests
+// and backoff after throttling server responses
+type hostLimiter struct {
+	slots    chan struct{} // concurrent requests slots
+	interval time.Duration // minimum interval between requests start
+	backoff  time.Duration // current backoff after throttling responses
+	next     time.Time     // earliest time of next request
+	mu       sync.Mutex    // mutex for requests scheduling
+}
+
+// newHostLimiter create new host limiter with given
+// concurrent requests cap and interval between requests
+func newHostLimiter(concurrency int, interval time.Duration) *hostLimiter {
+	return &hostLimiter{
+		slots:    make(chan struct{}, concurrency),
+		interval: interval,
+	}
+}
+
+// acquire wait free request slot and request time,
+// return function for slot releasing
+func (h *hostLimiter) acquire(ctx context.Context) (func(), error) {
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-h.slots }
+
+	// reserve next request time slot
+	h.mu.Lock()
+	now := time.Now()
+	if h.next.Before(now) {
+		h.next = now
+	}
+	wait := h.next.Sub(now)
+	h.next = h.next.Add(h.interval)
+	h.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	case <-timer.C:
+		return release, nil
+	}
+}
+
+// throttle postpone next requests to host after
+// 429 or 503 response with given Retry-After value.
+// Without Retry-After backoff is doubled on every throttling response.
+func (h *hostLimiter) throttle(retryAfter time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch {
+	case retryAfter > 0:
+		h.backoff = retryAfter
+	case h.backoff == 0:
+		h.backoff = minBackoff
+	default:
+		h.backoff *= 2
+	}
+	if h.backoff > maxBackoff {
+		h.backoff = maxBackoff
+	}
+
+	if next := time.Now().Add(h.backoff); next.After(h.next) {
+		h.next = next
+	}
+}
+
+// success reset backoff after successful response
+func (h *hostLimiter) success() {
+	h.mu.Lock()
+	h.backoff = 0
+	h.mu.Unlock()
+}
+
+// isThrottled check if response status means
+// server asks to slow down requests
+func isThrottled(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+// parseRetryAfter parse Retry-After header value
+// in seconds or HTTP-date format
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
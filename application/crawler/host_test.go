@@ -0,0 +1,97 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_hostLimiter_acquire(t *testing.T) {
+	h := newHostLimiter(3, 50*time.Millisecond)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := h.acquire(context.Background())
+		if err != nil {
+			t.Errorf("hostLimiter.acquire() error = %v", err)
+			return
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("hostLimiter.acquire() three requests took %s, want at least %s", elapsed, 100*time.Millisecond)
+	}
+}
+
+func Test_hostLimiter_concurrency(t *testing.T) {
+	h := newHostLimiter(1, 0)
+
+	release, _ := h.acquire(context.Background())
+
+	// second request should wait released slot
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := h.acquire(ctx); err == nil {
+		t.Errorf("hostLimiter.acquire() concurrency cap is exceeded")
+	}
+
+	release()
+	if _, err := h.acquire(context.Background()); err != nil {
+		t.Errorf("hostLimiter.acquire() error = %v", err)
+	}
+}
+
+func Test_hostLimiter_throttle(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter []time.Duration
+		want       time.Duration
+	}{
+		{"retryAfter", []time.Duration{30 * time.Second}, 30 * time.Second},
+		{"firstBackoff", []time.Duration{0}, minBackoff},
+		{"exponentialBackoff", []time.Duration{0, 0, 0}, 4 * minBackoff},
+		{"maxBackoff", []time.Duration{time.Hour}, maxBackoff},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHostLimiter(1, 0)
+			for _, retryAfter := range tt.retryAfter {
+				h.throttle(retryAfter)
+			}
+			if h.backoff != tt.want {
+				t.Errorf("hostLimiter.throttle() backoff = %v, want %v", h.backoff, tt.want)
+			}
+			if wait := time.Until(h.next); wait <= tt.want-time.Second {
+				t.Errorf("hostLimiter.throttle() next request in %v, want %v", wait, tt.want)
+			}
+
+			h.success()
+			if h.backoff != 0 {
+				t.Errorf("hostLimiter.success() backoff = %v, want %v", h.backoff, 0)
+			}
+		})
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2019, 2, 13, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"empty", "", 0},
+		{"seconds", "120", 2 * time.Minute},
+		{"date", now.Add(time.Minute).Format(http.TimeFormat), time.Minute},
+		{"pastDate", now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"invalid", "soon", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+// maxRedirects is maximum redirect hops of one page fetching
+const maxRedirects = 10
+
+var (
+	errRedirectLoop     = errors.New("redirect loop")
+	errTooManyRedirects = errors.New("too many redirects")
+	errExternalRedirect = errors.New("redirect to external host")
+)
+
+// request send GET request to given target following redirects
+// to site host, every redirect hop is recorded in given response.
+// Redirect to host out of site scope is recorded, but not followed.
+func (c *Crawler) request(ctx context.Context, target *url.URL, response *site.Response) (*http.Response, error) {
+	// redirects are followed manually to record every hop
+	client := *c.Client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	for {
+		req, err := http.NewRequest(http.MethodGet, target.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		// response without redirect Location is final
+		location, err := resp.Location()
+		if !isRedirect(resp.StatusCode) || err != nil {
+			return resp, nil
+		}
+		io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+
+		response.Redirects = append(response.Redirects, &site.Redirect{
+			Url:      target.String(),
+			Status:   resp.StatusCode,
+			Location: location.String(),
+		})
+
+		switch {
+		case !c.Site.InScope(&site.Url{URL: location}):
+			return nil, errExternalRedirect
+		case inRedirects(location.String(), response.Redirects):
+			return nil, errRedirectLoop
+		case len(response.Redirects) >= maxRedirects:
+			return nil, errTooManyRedirects
+		}
+		target = location
+	}
+}
+
+// isRedirect check if response status is redirect with Location
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// inRedirects check if given url was already requested in redirects chain
+func inRedirects(url string, redirects []*site.Redirect) bool {
+	for _, redirect := range redirects {
+		if redirect.Url == url {
+			return true
+		}
+	}
+	return false
+}
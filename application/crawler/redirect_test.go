@@ -0,0 +1,131 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+func TestCrawler_request(t *testing.T) {
+	server := httptest.NewServer(getRedirectHandler())
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		path          string
+		wantRedirects []*site.Redirect
+		wantErr       error
+	}{
+		{"noRedirect", "/new", nil, nil},
+		{"redirect", "/old", []*site.Redirect{
+			{Url: server.URL + "/old", Status: http.StatusMovedPermanently, Location: server.URL + "/older"},
+			{Url: server.URL + "/older", Status: http.StatusFound, Location: server.URL + "/new"},
+		}, nil},
+		{"external", "/external", []*site.Redirect{
+			{Url: server.URL + "/external", Status: http.StatusFound, Location: "https://monzo.com/"},
+		}, errExternalRedirect},
+		{"loop", "/loop", []*site.Redirect{
+			{Url: server.URL + "/loop", Status: http.StatusFound, Location: server.URL + "/loop/back"},
+			{Url: server.URL + "/loop/back", Status: http.StatusFound, Location: server.URL + "/loop"},
+		}, errRedirectLoop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := NewCrawler(getTestSite(server.URL).Url, false, 1)
+			target, _ := c.Site.Url.ParseUrl(server.URL + tt.path)
+
+			response := &site.Response{}
+			resp, err := c.request(context.Background(), target.URL, response)
+			if err != tt.wantErr {
+				t.Errorf("Crawler.request() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil {
+				resp.Body.Close()
+				if resp.Request.URL.Path != "/new" {
+					t.Errorf("Crawler.request() final url = %v, want %v", resp.Request.URL, server.URL+"/new")
+				}
+			}
+			if !reflect.DeepEqual(response.Redirects, tt.wantRedirects) {
+				t.Errorf("Crawler.request() redirects = %v, want %v", response.Redirects, tt.wantRedirects)
+			}
+		})
+	}
+}
+
+func TestCrawler_fetch_response(t *testing.T) {
+	server := httptest.NewServer(getRedirectHandler())
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		path      string
+		wantState site.PageState
+		want      site.Response
+	}{
+		{"page", "/new", site.Queued, site.Response{
+			Status:        http.StatusOK,
+			FinalUrl:      server.URL + "/new",
+			ContentType:   "text/html; charset=utf-8",
+			ContentLength: 22,
+			LastModified:  "2006-01-02T15:04:05Z",
+		}},
+		{"redirect", "/older", site.Queued, site.Response{
+			Status:        http.StatusOK,
+			FinalUrl:      server.URL + "/new",
+			ContentType:   "text/html; charset=utf-8",
+			ContentLength: 22,
+			LastModified:  "2006-01-02T15:04:05Z",
+			Redirects:     []*site.Redirect{{Url: server.URL + "/older", Status: http.StatusFound, Location: server.URL + "/new"}},
+		}},
+		{"external", "/external", site.Redirected, site.Response{
+			Status:        http.StatusFound,
+			FinalUrl:      "https://monzo.com/",
+			ContentLength: -1,
+			Redirects:     []*site.Redirect{{Url: server.URL + "/external", Status: http.StatusFound, Location: "https://monzo.com/"}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := NewCrawler(getTestSite(server.URL).Url, false, 1)
+			target, _ := c.Site.Url.ParseUrl(server.URL + tt.path)
+			page := site.NewPage(target)
+			page.State = site.Queued
+
+			if _, err := c.fetch(context.Background(), page); err != nil {
+				t.Errorf("Crawler.fetch() error = %v", err)
+				return
+			}
+			if page.State != tt.wantState {
+				t.Errorf("Crawler.fetch() page state = %v, want %v", page.State, tt.wantState)
+			}
+
+			page.Response.ResponseTime = 0
+			if !reflect.DeepEqual(*page.Response, tt.want) {
+				t.Errorf("Crawler.fetch() page response = %+v, want %+v", *page.Response, tt.want)
+			}
+		})
+	}
+}
+
+// getRedirectHandler return handler with internal,
+// external and looped redirects
+func getRedirectHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/old", http.RedirectHandler("/older", http.StatusMovedPermanently))
+	mux.Handle("/older", http.RedirectHandler("/new", http.StatusFound))
+	mux.Handle("/external", http.RedirectHandler("https://monzo.com/", http.StatusFound))
+	mux.Handle("/loop", http.RedirectHandler("/loop/back", http.StatusFound))
+	mux.Handle("/loop/back", http.RedirectHandler("/loop", http.StatusFound))
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		fmt.Fprint(w, `<a href="/old">Old</a>`)
+	})
+	return mux
+}
@@ -0,0 +1,77 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// maxRetryBackoff is maximum waiting time before next fetch attempt
+const maxRetryBackoff = time.Minute
+
+// statusError represent unsuccessful server response
+type statusError struct {
+	code   int
+	status string
+}
+
+// Error return response status as error message
+func (e *statusError) Error() string {
+	return fmt.Sprintf("server responded %s", e.status)
+}
+
+// isTransient check if fetch error is temporary
+// and page fetching can succeed on next attempt:
+// server errors, timeouts and connection errors
+func isTransient(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// retryBackoff return waiting time before next attempt after given
+// failed attempt: exponentially growing from base with random jitter,
+// which spreads retries of concurrent workers
+func retryBackoff(base time.Duration, attempt int) time.Duration {
+	backoff := base
+	for i := 1; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	// random backoff in [backoff/2, backoff)
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(backoff-half)))
+}
+
+// sleep wait given duration, return false if context is done earlier
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
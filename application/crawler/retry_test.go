@@ -0,0 +1,58 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func Test_isTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serverError", &statusError{http.StatusBadGateway, "502 Bad Gateway"}, true},
+		{"clientError", &statusError{http.StatusNotFound, "404 Not Found"}, false},
+		{"connection", &url.Error{Op: "Get", URL: "https://monzo.com", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, true},
+		{"timeout", &url.Error{Op: "Get", URL: "https://monzo.com", Err: context.DeadlineExceeded}, true},
+		{"unexpectedEOF", fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), true},
+		{"other", errors.New("unsupported protocol scheme"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransient(tt.err); got != tt.want {
+				t.Errorf("isTransient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_retryBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{"first", 1, 500 * time.Millisecond, time.Second},
+		{"third", 3, 2 * time.Second, 4 * time.Second},
+		{"capped", 20, maxRetryBackoff / 2, maxRetryBackoff},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := retryBackoff(time.Second, tt.attempt); got < tt.min || got >= tt.max {
+					t.Errorf("retryBackoff() = %v, want in [%v, %v)", got, tt.min, tt.max)
+					return
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+// Diff exit codes
+const (
+	ExitOK      = 0 // removed pages are within threshold
+	ExitRemoved = 1 // removed pages exceed threshold
+	ExitError   = 2 // invalid arguments or unreadable outputs
+)
+
+// Result represent changes between two crawls of the site
+type Result struct {
+	Old      string         `json:"old"`            // old crawl site Url
+	New      string         `json:"new"`            // new crawl site Url
+	OldPages int            `json:"old_pages"`      // total pages of old crawl
+	NewPages int            `json:"new_pages"`      // total pages of new crawl
+	Added    []string       `json:"added"`          // pages found only in new crawl
+	Removed  []string       `json:"removed"`        // pages found only in old crawl
+	Changed  []StatusChange `json:"status_changed"` // pages with changed response status or state
+	Links    []LinksChange  `json:"links_changed"`  // crawled pages with changed links
+}
+
+// StatusChange represent page with changed response status or crawling state
+type StatusChange struct {
+	Url       string `json:"url"`                  // page Url
+	OldStatus int    `json:"old_status,omitempty"` // old response status
+	NewStatus int    `json:"new_status,omitempty"` // new response status
+	OldState  string `json:"old_state"`            // old crawling state
+	NewState  string `json:"new_state"`            // new crawling state
+}
+
+// LinksChange represent page with added or removed links
+type LinksChange struct {
+	Url     string   `json:"url"`               // page Url
+	Added   []string `json:"added,omitempty"`   // links found only in new crawl
+	Removed []string `json:"removed,omitempty"` // links found only in old crawl
+}
+
+// Compare find changes of the site between old and new crawls,
+// pages and links are matched by canonical url keys,
+// links are compared only for pages crawled both times
+func Compare(old, new *Snapshot) *Result {
+	result := &Result{
+		Old:      old.Url,
+		New:      new.Url,
+		OldPages: len(old.Pages),
+		NewPages: len(new.Pages),
+		Added:    []string{},
+		Removed:  []string{},
+		Changed:  []StatusChange{},
+		Links:    []LinksChange{},
+	}
+
+	for key, newPage := range new.Pages {
+		if _, ok := old.Pages[key]; !ok {
+			result.Added = append(result.Added, newPage.Url)
+		}
+	}
+
+	for key, oldPage := range old.Pages {
+		newPage, ok := new.Pages[key]
+		if !ok {
+			result.Removed = append(result.Removed, oldPage.Url)
+			continue
+		}
+
+		if oldPage.Status != newPage.Status || oldPage.State != newPage.State {
+			result.Changed = append(result.Changed, StatusChange{
+				Url:       newPage.Url,
+				OldStatus: oldPage.Status,
+				NewStatus: newPage.Status,
+				OldState:  oldPage.State,
+				NewState:  newPage.State,
+			})
+		}
+
+		if oldPage.State != site.Crawled.String() || newPage.State != site.Crawled.String() {
+			continue
+		}
+		added, removed := difference(newPage.Links, oldPage.Links), difference(oldPage.Links, newPage.Links)
+		if len(added) > 0 || len(removed) > 0 {
+			result.Links = append(result.Links, LinksChange{Url: newPage.Url, Added: added, Removed: removed})
+		}
+	}
+
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+	sort.Slice(result.Changed, func(i, j int) bool { return result.Changed[i].Url < result.Changed[j].Url })
+	sort.Slice(result.Links, func(i, j int) bool { return result.Links[i].Url < result.Links[j].Url })
+	return result
+}
+
+// difference return sorted links of a missing in b,
+// links are compared by canonical url keys
+func difference(a, b []string) []string {
+	exists := make(map[string]bool, len(b))
+	for _, link := range b {
+		exists[normalizer.KeyString(link)] = true
+	}
+
+	var links []string
+	for _, link := range a {
+		if key := normalizer.KeyString(link); !exists[key] {
+			links = append(links, link)
+			exists[key] = true
+		}
+	}
+	sort.Strings(links)
+	return links
+}
+
+// Print write human-readable changes summary to given writer
+func (r *Result) Print(w io.Writer) {
+	fmt.Fprintf(w, "Compared %d pages at %s with %d pages at %s\n", r.OldPages, r.Old, r.NewPages, r.New)
+
+	if len(r.Added) > 0 {
+		fmt.Fprintf(w, "Added pages (%d):\n", len(r.Added))
+		for _, url := range r.Added {
+			fmt.Fprintf(w, "  + %s\n", url)
+		}
+	}
+	if len(r.Removed) > 0 {
+		fmt.Fprintf(w, "Removed pages (%d):\n", len(r.Removed))
+		for _, url := range r.Removed {
+			fmt.Fprintf(w, "  - %s\n", url)
+		}
+	}
+	if len(r.Changed) > 0 {
+		fmt.Fprintf(w, "Status changed (%d):\n", len(r.Changed))
+		for _, change := range r.Changed {
+			fmt.Fprintf(w, "  ~ %s: %s -> %s\n", change.Url, status(change.OldStatus, change.OldState), status(change.NewStatus, change.NewState))
+		}
+	}
+	if len(r.Links) > 0 {
+		fmt.Fprintf(w, "Links changed (%d):\n", len(r.Links))
+		for _, change := range r.Links {
+			fmt.Fprintf(w, "  %s\n", change.Url)
+			for _, link := range change.Added {
+				fmt.Fprintf(w, "    + %s\n", link)
+			}
+			for _, link := range change.Removed {
+				fmt.Fprintf(w, "    - %s\n", link)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "%d added, %d removed, %d status changed, %d links changed\n", len(r.Added), len(r.Removed), len(r.Changed), len(r.Links))
+}
+
+// status format response status with crawling state of the page
+func status(code int, state string) string {
+	if code == 0 {
+		return state
+	}
+	return fmt.Sprintf("%d %s", code, state)
+}
+
+// ExitCode return process exit code of diff result,
+// negative maxRemoved disables removed pages threshold
+func (r *Result) ExitCode(maxRemoved int) int {
+	if maxRemoved >= 0 && len(r.Removed) > maxRemoved {
+		return ExitRemoved
+	}
+	return ExitOK
+}
@@ -0,0 +1,240 @@
+package diff
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/andskur/web-crawler/application/site"
+	"github.com/andskur/web-crawler/application/writer/json"
+)
+
+func TestLoad(t *testing.T) {
+	want := map[string]Page{
+		"//monzo.com":        {"https://monzo.com", "crawled", 200, []string{"https://monzo.com/blog", "https://monzo.com/about"}},
+		"//monzo.com/blog":   {"https://monzo.com/blog", "crawled", 200, []string{"https://monzo.com/", "https://monzo.com/broken"}},
+		"//monzo.com/about":  {"https://monzo.com/about", "not_fetched", 0, nil},
+		"//monzo.com/broken": {"https://monzo.com/broken", "failed", 404, nil},
+	}
+
+	tests := []struct {
+		name    string
+		mapType string
+		want    map[string]Page
+		wantErr bool
+	}{
+		{"hash", "hash", want, false},
+		{"tree", "tree", want, false},
+		{"unsupported", "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := getTestSite()
+			switch tt.mapType {
+			case "hash":
+				s.PageTree = nil
+			case "tree":
+				s.HashMap = nil
+			default:
+				s.PageTree, s.HashMap = nil, nil
+			}
+			fileName := filepath.Join(t.TempDir(), "site.json")
+			file, _ := os.Create(fileName)
+			if err := (&json.WriterJson{}).Write(file, s); err != nil {
+				t.Fatal(err)
+			}
+			file.Close()
+
+			snapshot, err := Load(fileName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got := make(map[string]Page)
+			for key, page := range snapshot.Pages {
+				if len(page.Links) == 0 {
+					page.Links = nil
+				}
+				got[key] = *page
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSnapshot_sameCanonicalUrl(t *testing.T) {
+	tests := []struct {
+		name       string
+		firstState site.PageState
+		want       Page
+	}{
+		{"crawledPreferred", site.Failed, Page{"https://monzo.com/blog/", "crawled", 200, []string{"https://monzo.com/blog/a", "https://monzo.com/about"}}},
+		{"firstPreferred", site.Crawled, Page{"https://monzo.com/blog", "crawled", 200, []string{"https://monzo.com/about", "https://monzo.com/blog/a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := getTestSite()
+			blog := s.HashMap["https://monzo.com/blog"]
+			blog.State = tt.firstState
+			blog.Response = &site.Response{Status: 200}
+			blog.Links = nil
+			blog.AddSubPage("/about")
+
+			// the same page crawled by url with trailing slash
+			url, _ := site.ParseRequestURI("https://monzo.com/blog/")
+			slash := site.NewPage(url)
+			slash.State = site.Crawled
+			slash.Response = &site.Response{Status: 200}
+			slash.AddSubPage("/blog/a")
+			s.HashMap[url.String()] = slash
+
+			snapshot := NewSnapshot(s)
+			if len(snapshot.Pages) != 4 {
+				t.Errorf("NewSnapshot() pages = %v, want %v", len(snapshot.Pages), 4)
+			}
+			if got := snapshot.Pages["//monzo.com/blog"]; !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("NewSnapshot() merged page = %v, want %v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	result := Compare(getOldSnapshot(), getNewSnapshot())
+
+	want := &Result{
+		Old:      "https://monzo.com",
+		New:      "https://monzo.com",
+		OldPages: 4,
+		NewPages: 4,
+		Added:    []string{"https://monzo.com/careers"},
+		Removed:  []string{"https://monzo.com/about"},
+		Changed: []StatusChange{
+			{Url: "https://monzo.com/blog", OldStatus: 200, NewStatus: 500, OldState: "crawled", NewState: "failed"},
+		},
+		Links: []LinksChange{
+			{Url: "https://monzo.com", Added: []string{"https://monzo.com/careers"}, Removed: []string{"https://monzo.com/about"}},
+		},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Compare() = %+v, want %+v", result, want)
+	}
+}
+
+func TestCompare_canonicalUrls(t *testing.T) {
+	// the same site crawled over http with trailing slashes
+	new := getTestSnapshot("http://monzo.com/",
+		&Page{"http://monzo.com/", "crawled", 200, []string{"http://monzo.com/about/", "http://monzo.com/blog/"}},
+		&Page{"http://monzo.com/about/", "crawled", 200, nil},
+		&Page{"http://monzo.com/blog/", "crawled", 200, []string{"http://monzo.com/blog/a/"}},
+		&Page{"http://monzo.com/blog/a/index.html", "crawled", 200, nil},
+	)
+
+	result := Compare(getOldSnapshot(), new)
+	if len(result.Added) > 0 || len(result.Removed) > 0 || len(result.Changed) > 0 || len(result.Links) > 0 {
+		t.Errorf("Compare() = %+v, want no changes", result)
+	}
+}
+
+func TestResult_Print(t *testing.T) {
+	var buf bytes.Buffer
+	Compare(getOldSnapshot(), getNewSnapshot()).Print(&buf)
+
+	want := `Compared 4 pages at https://monzo.com with 4 pages at https://monzo.com
+Added pages (1):
+  + https://monzo.com/careers
+Removed pages (1):
+  - https://monzo.com/about
+Status changed (1):
+  ~ https://monzo.com/blog: 200 crawled -> 500 failed
+Links changed (1):
+  https://monzo.com
+    + https://monzo.com/careers
+    - https://monzo.com/about
+1 added, 1 removed, 1 status changed, 1 links changed
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Result.Print() = %s, want %s", got, want)
+	}
+}
+
+func TestResult_ExitCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRemoved int
+		want       int
+	}{
+		{"disabled", -1, ExitOK},
+		{"exceeded", 0, ExitRemoved},
+		{"within", 1, ExitOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Compare(getOldSnapshot(), getNewSnapshot())
+			if got := result.ExitCode(tt.maxRemoved); got != tt.want {
+				t.Errorf("Result.ExitCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// getTestSite return crawled site with blog section,
+// failed and not fetched pages
+func getTestSite() *site.Site {
+	url, _ := site.ParseRequestURI("https://monzo.com")
+	s := site.NewSite(url)
+	s.PageTree.State = site.Crawled
+	s.PageTree.Response = &site.Response{Status: 200}
+
+	blog, _ := s.PageTree.AddSubPage("/blog")
+	blog.State = site.Crawled
+	blog.Response = &site.Response{Status: 200}
+	about, _ := s.PageTree.AddSubPage("/about")
+	about.State = site.NotFetched
+	blog.AddSubPage("/")
+	broken, _ := blog.AddSubPage("/broken")
+	broken.State = site.Failed
+	broken.Response = &site.Response{Status: 404}
+
+	for _, page := range []*site.Page{blog, about, broken} {
+		s.HashMap[page.Url.String()] = page
+	}
+	return s
+}
+
+// getOldSnapshot return snapshot of previous site crawl
+func getOldSnapshot() *Snapshot {
+	return getTestSnapshot("https://monzo.com",
+		&Page{"https://monzo.com", "crawled", 200, []string{"https://monzo.com/about", "https://monzo.com/blog"}},
+		&Page{"https://monzo.com/about", "crawled", 200, nil},
+		&Page{"https://monzo.com/blog", "crawled", 200, []string{"https://monzo.com/blog/a"}},
+		&Page{"https://monzo.com/blog/a", "crawled", 200, nil},
+	)
+}
+
+// getNewSnapshot return snapshot of current site crawl
+func getNewSnapshot() *Snapshot {
+	return getTestSnapshot("https://monzo.com",
+		&Page{"https://monzo.com", "crawled", 200, []string{"https://monzo.com/blog", "https://monzo.com/careers"}},
+		&Page{"https://monzo.com/blog", "failed", 500, nil},
+		&Page{"https://monzo.com/blog/a", "crawled", 200, nil},
+		&Page{"https://monzo.com/careers", "crawled", 200, nil},
+	)
+}
+
+// getTestSnapshot return snapshot of given pages keyed by canonical urls
+func getTestSnapshot(url string, pages ...*Page) *Snapshot {
+	snapshot := &Snapshot{Url: url, Pages: make(map[string]*Page, len(pages))}
+	for _, page := range pages {
+		snapshot.Pages[normalizer.KeyString(page.Url)] = page
+	}
+	return snapshot
+}
@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"github.com/andskur/web-crawler/application/reader"
+	"github.com/andskur/web-crawler/application/site"
+)
+
+// normalizer is canonical urls normalizer of compared pages,
+// http and https pages of both crawls are the same pages
+var normalizer = &site.Normalizer{IgnoreTrailingSlash: true, IndexFiles: site.DefaultIndexFiles, IgnoreScheme: true}
+
+// Snapshot represent site pages loaded from crawl output
+type Snapshot struct {
+	Url   string           // basic site Url
+	Pages map[string]*Page // site pages by canonical url key
+}
+
+// Page represent compared site page
+type Page struct {
+	Url    string   // page Url
+	State  string   // page crawling state
+	Status int      // response status, 0 - not fetched or network error
+	Links  []string // urls of page links
+}
+
+// Load read site snapshot from Json or Xml output file
+func Load(fileName string) (*Snapshot, error) {
+	s, err := reader.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return NewSnapshot(s), nil
+}
+
+// NewSnapshot create snapshot of given site pages keyed by canonical urls,
+// pages with the same canonical url are merged into one
+func NewSnapshot(s *site.Site) *Snapshot {
+	snapshot := &Snapshot{Url: s.Url.String(), Pages: make(map[string]*Page)}
+	for _, page := range s.Pages() {
+		p := &Page{Url: page.Url.String(), State: page.State.String()}
+		if page.Response != nil {
+			p.Status = page.Response.Status
+		}
+		for _, link := range page.Links {
+			p.Links = append(p.Links, link.Url.String())
+		}
+
+		key := normalizer.Key(page.Url)
+		if same, ok := snapshot.Pages[key]; ok {
+			p = merge(same, p)
+		}
+		snapshot.Pages[key] = p
+	}
+	return snapshot
+}
+
+// merge merge two pages with the same canonical url: crawled page is
+// preferred over page of other state, the first one over the same state,
+// links of both pages are kept
+func merge(first, second *Page) *Page {
+	merged, other := first, second
+	crawled := site.Crawled.String()
+	if first.State != crawled && second.State == crawled {
+		merged, other = second, first
+	}
+	merged.Links = append(merged.Links, other.Links...)
+	return merged
+}
@@ -0,0 +1,52 @@
+package reader
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andskur/web-crawler/application/site"
+	"github.com/andskur/web-crawler/application/writer"
+)
+
+var ErrUnsupportedReader = errors.New("unsupported reader type, json or xml site output expected")
+
+// Read decode site from given reader in given output format,
+// hash map and page tree outputs are supported
+func Read(r io.Reader, format writer.Format) (*site.Site, error) {
+	s := &site.Site{}
+	var err error
+	switch format {
+	case writer.JSON:
+		err = json.NewDecoder(r).Decode(s)
+	case writer.XML:
+		err = xml.NewDecoder(r).Decode(s)
+	default:
+		return nil, ErrUnsupportedReader
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ReadFile decode site from given output file,
+// output format is detected by file extension
+func ReadFile(fileName string) (*site.Site, error) {
+	format, err := writer.ParseFormats(strings.TrimPrefix(filepath.Ext(fileName), "."))
+	if err != nil {
+		return nil, ErrUnsupportedReader
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Read(file, format)
+}
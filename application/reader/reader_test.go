@@ -0,0 +1,154 @@
+package reader
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andskur/web-crawler/application/site"
+	"github.com/andskur/web-crawler/application/writer"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  writer.Format
+		mapType string
+	}{
+		{"jsonHash", writer.JSON, "hash"},
+		{"jsonTree", writer.JSON, "tree"},
+		{"xmlHash", writer.XML, "hash"},
+		{"xmlTree", writer.XML, "tree"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrt, _ := writer.NewWriter(tt.format, writer.Options{})
+			var want bytes.Buffer
+			if err := wrt.Write(&want, getTestSite(tt.mapType)); err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := Read(bytes.NewReader(want.Bytes()), tt.format)
+			if err != nil {
+				t.Errorf("Read() error = %v", err)
+				return
+			}
+			if s.Version != site.SchemaVersion {
+				t.Errorf("Read() version = %v, want %v", s.Version, site.SchemaVersion)
+			}
+			if pages := len(s.Pages()); pages != 4 {
+				t.Errorf("Read() pages = %v, want 4", pages)
+			}
+
+			// decoded site is written back unchanged
+			var got bytes.Buffer
+			if err := wrt.Write(&got, s); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("Read() rewritten = %s, want %s", got.String(), want.String())
+			}
+		})
+	}
+}
+
+func TestRead_errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format writer.Format
+		data   string
+	}{
+		{"newerVersion", writer.JSON, `{"version": 2, "url": "https://monzo.com", "map": [{"url": "https://monzo.com"}]}`},
+		{"newerVersionXml", writer.XML, `<site version="2"><url>https://monzo.com</url></site>`},
+		{"noUrl", writer.JSON, `{"version": 1}`},
+		{"noPages", writer.JSON, `{"version": 1, "url": "https://monzo.com"}`},
+		{"otherXml", writer.XML, `<urlset><url><loc>https://monzo.com</loc></url></urlset>`},
+		{"unsupported", writer.CSV, `source,target`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Read(strings.NewReader(tt.data), tt.format); err == nil {
+				t.Errorf("Read() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		data     string
+		wantErr  bool
+	}{
+		{"json", "site.json", `{"url": "https://monzo.com", "total_pages": 1, "map": [{"url": "https://monzo.com", "state": "crawled"}]}`, false},
+		{"xml", "site.xml", `<site><url>https://monzo.com</url><map><page><url>https://monzo.com</url><state>crawled</state></page></map></site>`, false},
+		{"unsupported", "site.csv", `source,target`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), tt.fileName)
+			if err := ioutil.WriteFile(fileName, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := ReadFile(fileName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if page := s.HashMap["https://monzo.com"]; page == nil || page.State != site.Crawled {
+				t.Errorf("ReadFile() map = %v, want crawled entry page", s.HashMap)
+			}
+		})
+	}
+}
+
+// getTestSite return crawled site of given map type
+// with skipped, failed and not fetched pages
+func getTestSite(mapType string) *site.Site {
+	url, _ := site.ParseRequestURI("https://monzo.com")
+	s := site.NewSite(url)
+	s.TotalPages = 3
+	s.PageTree.State = site.Crawled
+	s.PageTree.Attempts = 1
+	s.PageTree.Response = &site.Response{Status: 200, FinalUrl: "https://monzo.com", ContentType: "text/html", ContentLength: 140}
+
+	blog, _ := s.PageTree.AddSubPage("/blog")
+	blog.State = site.Crawled
+	blog.Attempts = 1
+	blog.Response = &site.Response{
+		Status:    200,
+		FinalUrl:  "https://monzo.com/blog/",
+		Redirects: []*site.Redirect{{Url: "https://monzo.com/blog", Status: 301, Location: "https://monzo.com/blog/"}},
+	}
+	about, _ := s.PageTree.AddSubPage("/about")
+	about.State = site.NotFetched
+	blog.AddSubPage("/")
+	broken, _ := blog.AddSubPage("/broken")
+	broken.State = site.Failed
+	broken.Attempts = 2
+	broken.Error = "server responded 404 Not Found"
+	broken.Response = &site.Response{Status: 404, FinalUrl: "https://monzo.com/broken", LastModified: "2006-01-02T15:04:05Z"}
+
+	for _, page := range []*site.Page{blog, about, broken} {
+		s.HashMap[page.Url.String()] = page
+	}
+	s.SkipPage("https://monzo.com/admin", "disallowed by robots.txt")
+	s.LimitReached(site.LimitPages)
+	s.AddSource("https://monzo.com/broken", site.Source{Url: "https://monzo.com/blog", Text: "Broken"})
+	s.FindBrokenLinks()
+	s.Incomplete = true
+
+	if mapType == "hash" {
+		s.PageTree = nil
+	} else {
+		s.HashMap = nil
+	}
+	return s
+}
@@ -0,0 +1,262 @@
+package robots
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSize is maximum robots.txt size which will be parsed
+const maxSize = 500 << 10
+
+// Robots represent parsed robots.txt rules group
+// for one crawler user-agent
+type Robots struct {
+	Rules      []Rule        // Allow and Disallow rules of matched group
+	CrawlDelay time.Duration // minimum delay between requests
+}
+
+// Rule represent single robots.txt Allow or Disallow rule
+type Rule struct {
+	Allow   bool   // true for Allow rule, false for Disallow
+	Pattern string // path pattern with optional * and $ wildcards
+}
+
+// String return rule as it written in robots.txt
+func (r Rule) String() string {
+	if r.Allow {
+		return "Allow: " + r.Pattern
+	}
+	return "Disallow: " + r.Pattern
+}
+
+// group represent robots.txt group of rules
+// for one or more user-agents
+type group struct {
+	agents     []string
+	rules      []Rule
+	crawlDelay time.Duration
+}
+
+// AllowAll return Robots instance without any restrictions
+func AllowAll() *Robots {
+	return &Robots{}
+}
+
+// DisallowAll return Robots instance which disallow every page
+func DisallowAll() *Robots {
+	return &Robots{Rules: []Rule{{Allow: false, Pattern: "/"}}}
+}
+
+// Fetch request robots.txt of given target host and parse rules for given user-agent.
+// Robots.txt which is not found is treated as allow all, unauthorized and
+// forbidden one as disallow all, server errors are returned as errors.
+func Fetch(ctx context.Context, client *http.Client, target *url.URL, userAgent string) (*Robots, error) {
+	robotsURL := &url.URL{Scheme: target.Scheme, Host: target.Host, Path: "/robots.txt"}
+
+	req, err := http.NewRequest(http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return nil, fmt.Errorf("robots.txt server responded %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return DisallowAll(), nil
+	case resp.StatusCode >= 400:
+		return AllowAll(), nil
+	case resp.StatusCode >= 300:
+		return nil, fmt.Errorf("unexpected robots.txt response status %d", resp.StatusCode)
+	}
+
+	return Parse(io.LimitReader(resp.Body, maxSize), userAgent)
+}
+
+// Parse parse robots.txt from given reader and
+// return rules of group matched given user-agent.
+// If no group match user-agent, "*" group is used.
+func Parse(r io.Reader, userAgent string) (*Robots, error) {
+	groups, err := parseGroups(r)
+	if err != nil {
+		return nil, err
+	}
+
+	agent := productToken(userAgent)
+
+	robots := AllowAll()
+	var matched, wildcard []*group
+	for _, g := range groups {
+		switch {
+		case g.hasAgent(agent):
+			matched = append(matched, g)
+		case g.hasAgent("*"):
+			wildcard = append(wildcard, g)
+		}
+	}
+	if len(matched) == 0 {
+		matched = wildcard
+	}
+
+	// merge all matched groups
+	for _, g := range matched {
+		robots.Rules = append(robots.Rules, g.rules...)
+		if g.crawlDelay > robots.CrawlDelay {
+			robots.CrawlDelay = g.crawlDelay
+		}
+	}
+	return robots, nil
+}
+
+// Allowed check if given url path allowed to be crawled.
+// Return matched rule, if any.
+func (r *Robots) Allowed(u *url.URL) (bool, *Rule) {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	// robots.txt itself is always allowed
+	if path == "/robots.txt" {
+		return true, nil
+	}
+
+	// the most specific (longest) rule wins, Allow wins on equal length
+	var best *Rule
+	for i, rule := range r.Rules {
+		if !match(rule.Pattern, path) {
+			continue
+		}
+		if best == nil || len(rule.Pattern) > len(best.Pattern) ||
+			(len(rule.Pattern) == len(best.Pattern) && rule.Allow) {
+			best = &r.Rules[i]
+		}
+	}
+	if best == nil {
+		return true, nil
+	}
+	return best.Allow, best
+}
+
+// parseGroups parse robots.txt content to groups of rules
+func parseGroups(r io.Reader) ([]*group, error) {
+	var (
+		groups  []*group
+		current *group
+		// true if last parsed line was user-agent line
+		agentLine bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := parseLine(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "user-agent":
+			// sequential user-agent lines belongs to the same group
+			if current == nil || !agentLine {
+				current = &group{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, productToken(value))
+			agentLine = true
+			continue
+		case "allow", "disallow":
+			// empty Disallow means nothing is disallowed
+			if current != nil && value != "" {
+				current.rules = append(current.rules, Rule{Allow: key == "allow", Pattern: value})
+			}
+		case "crawl-delay":
+			if current == nil {
+				break
+			}
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+		agentLine = false
+	}
+	return groups, scanner.Err()
+}
+
+// hasAgent check if group belongs to given user-agent
+func (g *group) hasAgent(agent string) bool {
+	for _, a := range g.agents {
+		if a == agent {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLine split robots.txt line to lowercase key and value,
+// comments and invalid lines are skipped
+func parseLine(line string) (key, value string, ok bool) {
+	if idx := strings.Index(line, "#"); idx != -1 {
+		line = line[:idx]
+	}
+
+	idx := strings.Index(line, ":")
+	if idx == -1 {
+		return "", "", false
+	}
+
+	key = strings.ToLower(strings.TrimSpace(line[:idx]))
+	value = strings.TrimSpace(line[idx+1:])
+	return key, value, key != ""
+}
+
+// productToken return lowercase product name
+// from given user-agent, e.g. "web-crawler/1.0" -> "web-crawler"
+func productToken(userAgent string) string {
+	token := strings.TrimSpace(userAgent)
+	if idx := strings.IndexAny(token, "/ "); idx != -1 {
+		token = token[:idx]
+	}
+	return strings.ToLower(token)
+}
+
+// match check if given path match robots.txt pattern
+// with * (any sequence) and $ (end of path) wildcards
+func match(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+
+	for i, part := range parts[1:] {
+		// last part of anchored pattern should match the end of path
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(path[pos:], part)
+		}
+		idx := strings.Index(path[pos:], part)
+		if idx == -1 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	return !anchored || pos == len(path)
+}
@@ -0,0 +1,167 @@
+package robots
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRobots = `
+# comment line
+User-agent: *
+Disallow: /admin
+Allow: /admin/public
+Disallow: /*.zip$
+Disallow: /search?
+Crawl-delay: 2
+
+User-agent: Web-Crawler
+User-agent: other-bot
+Disallow: /private # private section
+Disallow:
+Crawl-delay: 0.5
+`
+
+func TestParse(t *testing.T) {
+	type args struct {
+		content   string
+		userAgent string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *Robots
+		wantErr bool
+	}{
+		{"wildcardGroup", args{testRobots, "unknown-bot"}, &Robots{
+			Rules: []Rule{
+				{false, "/admin"},
+				{true, "/admin/public"},
+				{false, "/*.zip$"},
+				{false, "/search?"},
+			},
+			CrawlDelay: 2 * time.Second,
+		}, false},
+		{"agentGroup", args{testRobots, "web-crawler/1.0"}, &Robots{
+			Rules:      []Rule{{false, "/private"}},
+			CrawlDelay: 500 * time.Millisecond,
+		}, false},
+		{"empty", args{"", "web-crawler"}, &Robots{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.args.content), tt.args.userAgent)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRobots_Allowed(t *testing.T) {
+	robots, _ := Parse(strings.NewReader(testRobots), "unknown-bot")
+
+	tests := []struct {
+		name     string
+		url      string
+		want     bool
+		wantRule *Rule
+	}{
+		{"root", "https://monzo.com", true, nil},
+		{"disallowed", "https://monzo.com/admin/users", false, &Rule{false, "/admin"}},
+		{"longestAllow", "https://monzo.com/admin/public/page", true, &Rule{true, "/admin/public"}},
+		{"wildcardEnd", "https://monzo.com/files/archive.zip", false, &Rule{false, "/*.zip$"}},
+		{"wildcardNotEnd", "https://monzo.com/files/archive.zip.html", true, nil},
+		{"query", "https://monzo.com/search?q=monzo", false, &Rule{false, "/search?"}},
+		{"robots", "https://monzo.com/robots.txt", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := url.Parse(tt.url)
+			got, rule := robots.Allowed(u)
+			if got != tt.want {
+				t.Errorf("Robots.Allowed() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(rule, tt.wantRule) {
+				t.Errorf("Robots.Allowed() rule = %v, want %v", rule, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestFetch(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    *Robots
+		wantErr bool
+	}{
+		{"found", http.StatusOK, "User-agent: *\nDisallow: /admin", &Robots{Rules: []Rule{{false, "/admin"}}}, false},
+		{"notFound", http.StatusNotFound, "", AllowAll(), false},
+		{"unauthorized", http.StatusUnauthorized, "", DisallowAll(), false},
+		{"forbidden", http.StatusForbidden, "", DisallowAll(), false},
+		{"serverError", http.StatusServiceUnavailable, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/robots.txt" {
+					http.NotFound(w, r)
+					return
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			target, _ := url.Parse(server.URL + "/some/page")
+			got, err := Fetch(context.Background(), server.Client(), target, "web-crawler")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Fetch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fetch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_match(t *testing.T) {
+	type args struct {
+		pattern string
+		path    string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{"prefix", args{"/fish", "/fish.html"}, true},
+		{"notPrefix", args{"/fish", "/Fish.asp"}, false},
+		{"wildcard", args{"/fish*.php", "/fish/salmon.php"}, true},
+		{"wildcardMiddle", args{"/*.php", "/folder/filename.php?parameters"}, true},
+		{"anchored", args{"/*.php$", "/filename.php"}, true},
+		{"anchoredQuery", args{"/*.php$", "/filename.php?parameters"}, false},
+		{"anchoredExact", args{"/fish$", "/fish"}, true},
+		{"anchoredExactLonger", args{"/fish$", "/fish/"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := match(tt.args.pattern, tt.args.path); got != tt.want {
+				t.Errorf("match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
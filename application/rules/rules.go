@@ -0,0 +1,206 @@
+package rules
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// regexpPrefix is prefix of rule patterns which are regular expressions
+const regexpPrefix = "re:"
+
+var errInvalidRule = errors.New("rule should be in \"include {pattern}\" or \"exclude {pattern}\" format")
+
+// Rule represent single include or exclude url rule
+type Rule struct {
+	Include bool           // true for include rule, false for exclude
+	Pattern string         // glob pattern, or regular expression with "re:" prefix
+	re      *regexp.Regexp // compiled pattern
+	target  target         // matched part of url
+}
+
+// target represent part of url matched by rule pattern
+type target int
+
+// available target constants
+const (
+	targetPath target = iota // url path
+	targetName               // last segment of url path
+	targetUrl                // whole url
+)
+
+// NewRule create new include or exclude rule from given pattern.
+// Glob patterns starting with "/" match url path, patterns ending with
+// "/" match directory with everything under it, patterns without "/"
+// match last path segment and patterns with "://" match whole url.
+// "*" matches any characters except "/", "**" matches any characters.
+// Regular expressions match any part of whole url.
+func NewRule(include bool, pattern string) (*Rule, error) {
+	if pattern == "" {
+		return nil, errInvalidRule
+	}
+	rule := &Rule{Include: include, Pattern: pattern}
+
+	// regular expression pattern
+	if strings.HasPrefix(pattern, regexpPrefix) {
+		re, err := regexp.Compile(strings.TrimPrefix(pattern, regexpPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %v", pattern, err)
+		}
+		rule.re, rule.target = re, targetUrl
+		return rule, nil
+	}
+
+	// glob pattern
+	glob := pattern
+	switch {
+	case strings.Contains(glob, "://"):
+		rule.target = targetUrl
+	case !strings.Contains(glob, "/"):
+		rule.target = targetName
+	case !strings.HasPrefix(glob, "/"):
+		glob = "/" + glob
+	}
+	if rule.target != targetName && strings.HasSuffix(glob, "/") {
+		glob += "**"
+	}
+	rule.re = regexp.MustCompile("^" + globToRegexp(glob) + "$")
+	return rule, nil
+}
+
+// ParseRule parse rule in "include {pattern}" or "exclude {pattern}" format,
+// regular expression patterns may contain spaces
+func ParseRule(line string) (*Rule, error) {
+	i := strings.IndexAny(line, " \t")
+	if i == -1 {
+		return nil, errInvalidRule
+	}
+	action, pattern := line[:i], strings.TrimSpace(line[i:])
+	switch action {
+	case "include":
+		return NewRule(true, pattern)
+	case "exclude":
+		return NewRule(false, pattern)
+	default:
+		return nil, errInvalidRule
+	}
+}
+
+// String return rule as it written in rules file
+func (r *Rule) String() string {
+	if r.Include {
+		return "include " + r.Pattern
+	}
+	return "exclude " + r.Pattern
+}
+
+// Match check if given url is matched by rule
+func (r *Rule) Match(u *url.URL) bool {
+	switch r.target {
+	case targetUrl:
+		withoutFragment := *u
+		withoutFragment.Fragment, withoutFragment.RawFragment = "", ""
+		return r.re.MatchString(withoutFragment.String())
+	case targetName:
+		return r.re.MatchString(path.Base("/" + u.Path))
+	default:
+		p := u.Path
+		if p == "" {
+			p = "/"
+		}
+		return r.re.MatchString(p)
+	}
+}
+
+// Rules represent include and exclude url rules of the crawl
+type Rules struct {
+	Include []*Rule // urls matched by none of include rules are excluded, empty - all urls are included
+	Exclude []*Rule // urls matched by any of exclude rules are excluded
+}
+
+// Add add given rule to include or exclude rules
+func (r *Rules) Add(rule *Rule) {
+	if rule.Include {
+		r.Include = append(r.Include, rule)
+	} else {
+		r.Exclude = append(r.Exclude, rule)
+	}
+}
+
+// Parse parse rules from given reader with one rule per line,
+// empty lines and lines starting with # are ignored
+func Parse(reader io.Reader) (*Rules, error) {
+	rules := &Rules{}
+	scanner := bufio.NewScanner(reader)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule, err := ParseRule(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		rules.Add(rule)
+	}
+	return rules, scanner.Err()
+}
+
+// Load read rules from given file
+func Load(fileName string) (*Rules, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Allowed check if given url is allowed to be crawled by rules:
+// it is not matched by any exclude rule and matched by one of include
+// rules, if any. Return matched exclude rule, nil if url is excluded
+// because it is not matched by include rules.
+func (r *Rules) Allowed(u *url.URL) (bool, *Rule) {
+	if r == nil {
+		return true, nil
+	}
+	for _, rule := range r.Exclude {
+		if rule.Match(u) {
+			return false, rule
+		}
+	}
+	if len(r.Include) == 0 {
+		return true, nil
+	}
+	for _, rule := range r.Include {
+		if rule.Match(u) {
+			return true, rule
+		}
+	}
+	return false, nil
+}
+
+// globToRegexp convert given glob pattern to regular expression
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case c == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,147 @@
+package rules
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRule_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"directory", "/docs/", "https://monzo.com/docs/", true},
+		{"directorySubpage", "/docs/", "https://monzo.com/docs/api/intro", true},
+		{"directoryOther", "/docs/", "https://monzo.com/documents", false},
+		{"pathStar", "/blog/*", "https://monzo.com/blog/post", true},
+		{"pathStarNested", "/blog/*", "https://monzo.com/blog/2020/post", false},
+		{"pathDoubleStar", "/blog/**/post", "https://monzo.com/blog/2020/01/post", true},
+		{"relativePath", "admin/*", "https://monzo.com/admin/users", true},
+		{"questionMark", "/v?/api", "https://monzo.com/v2/api", true},
+		{"exactPath", "/admin", "https://monzo.com/admin/users", false},
+		{"rootPath", "/", "https://monzo.com", true},
+		{"name", "*.zip", "https://monzo.com/files/archive.zip", true},
+		{"nameOther", "*.zip", "https://monzo.com/files/archive.zip.html", false},
+		{"nameQuery", "*.zip", "https://monzo.com/download?file=a.zip", false},
+		{"wholeUrl", "https://monzo.com/legal/**", "https://monzo.com/legal/terms?v=2", true},
+		{"wholeUrlOtherScheme", "https://monzo.com/legal/**", "http://monzo.com/legal/terms", false},
+		{"regexp", `re:\.(pdf|zip)$`, "https://monzo.com/files/report.pdf", true},
+		{"regexpQuery", "re:[?&]sort=", "https://monzo.com/blog?page=2&sort=new", true},
+		{"regexpFragment", "re:#", "https://monzo.com/blog#comments", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := NewRule(false, tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			u, _ := url.Parse(tt.url)
+			if got := rule.Match(u); got != tt.want {
+				t.Errorf("Rule.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		wantErr bool
+	}{
+		{"glob", "/docs/**", false},
+		{"regexp", "re:^https://monzo.com/docs", false},
+		{"empty", "", true},
+		{"invalidRegexp", "re:(docs", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRule(true, tt.pattern); (err != nil) != tt.wantErr {
+				t.Errorf("NewRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantInclude []string
+		wantExclude []string
+		wantErr     bool
+	}{
+		{"rules", "# docs only\ninclude /docs/\n\nexclude *.zip\nexclude re:/docs/old version/\n", []string{"include /docs/"}, []string{"exclude *.zip", "exclude re:/docs/old version/"}, false},
+		{"empty", "", nil, nil, false},
+		{"unknownAction", "allow /docs/", nil, nil, true},
+		{"noPattern", "exclude", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if include := ruleStrings(got.Include); strings.Join(include, "\n") != strings.Join(tt.wantInclude, "\n") {
+				t.Errorf("Parse() include = %v, want %v", include, tt.wantInclude)
+			}
+			if exclude := ruleStrings(got.Exclude); strings.Join(exclude, "\n") != strings.Join(tt.wantExclude, "\n") {
+				t.Errorf("Parse() exclude = %v, want %v", exclude, tt.wantExclude)
+			}
+		})
+	}
+}
+
+func TestRules_Allowed(t *testing.T) {
+	rules, err := Parse(strings.NewReader("include /docs/\ninclude /blog/\nexclude /docs/internal/\nexclude *.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		rules    *Rules
+		url      string
+		want     bool
+		wantRule string
+	}{
+		{"included", rules, "https://monzo.com/docs/api", true, "include /docs/"},
+		{"excluded", rules, "https://monzo.com/docs/internal/plan", false, "exclude /docs/internal/"},
+		{"excludedByName", rules, "https://monzo.com/blog/files.zip", false, "exclude *.zip"},
+		{"notIncluded", rules, "https://monzo.com/careers", false, ""},
+		{"noRules", nil, "https://monzo.com/careers", true, ""},
+		{"noIncludeRules", &Rules{Exclude: rules.Exclude}, "https://monzo.com/careers", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := url.Parse(tt.url)
+			got, rule := tt.rules.Allowed(u)
+			if got != tt.want {
+				t.Errorf("Rules.Allowed() = %v, want %v", got, tt.want)
+			}
+			var gotRule string
+			if rule != nil {
+				gotRule = rule.String()
+			}
+			if gotRule != tt.wantRule {
+				t.Errorf("Rules.Allowed() rule = %v, want %v", gotRule, tt.wantRule)
+			}
+		})
+	}
+}
+
+// ruleStrings return string representations of given rules
+func ruleStrings(rules []*Rule) []string {
+	var s []string
+	for _, rule := range rules {
+		s = append(s, rule.String())
+	}
+	return s
+}
@@ -0,0 +1,144 @@
+package site
+
+import (
+	"encoding/xml"
+	"sort"
+)
+
+// Source represent link to the page from other site page
+type Source struct {
+	Url  string `json:"url" xml:"url"`   // source page Url
+	Text string `json:"text" xml:"text"` // link anchor text
+}
+
+// BrokenLink represent failed site page
+// with all source pages linking to it
+type BrokenLink struct {
+	Url     string   `json:"url" xml:"url"`                           // broken page Url
+	Status  int      `json:"status,omitempty" xml:"status,omitempty"` // response status, 0 - network error
+	Error   string   `json:"error" xml:"error"`                       // final fetch error
+	Sources []Source `json:"sources" xml:"sources>source"`            // pages linking to broken page
+}
+
+// BrokenLinks represent broken links structure type
+type BrokenLinks []*BrokenLink
+
+// MarshalXML correct formatted XML marshaling
+// for Broken Links structure type
+func (b BrokenLinks) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Links []*BrokenLink `xml:"link"`
+	}{
+		Links: b}, start)
+}
+
+// UnmarshalXML correct formatted XML unmarshaling
+// for Broken Links structure type
+func (b *BrokenLinks) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var broken struct {
+		Links []*BrokenLink `xml:"link"`
+	}
+	if err := d.DecodeElement(&broken, &start); err != nil {
+		return err
+	}
+	*b = broken.Links
+	return nil
+}
+
+// BrokenLinksReport represent broken links report of the site
+type BrokenLinksReport struct {
+	XMLName xml.Name    `json:"-" xml:"broken_links"`
+	Url     *Url        `json:"url" xml:"url"`     // basic site Url
+	Total   int         `json:"total" xml:"total"` // total broken links count
+	Links   BrokenLinks `json:"links" xml:"links"` // broken links with source pages
+}
+
+// NewBrokenLinksReport create broken links report of given site
+func NewBrokenLinksReport(s *Site) *BrokenLinksReport {
+	links := s.Broken
+	if links == nil {
+		links = BrokenLinks{}
+	}
+	return &BrokenLinksReport{Url: s.Url, Total: len(links), Links: links}
+}
+
+// AddSource record link to given target page from source page
+func (s *Site) AddSource(target string, source Source) {
+	s.mu.Lock()
+	key := s.normalizer.KeyString(target)
+	s.Sources[key] = append(s.Sources[key], source)
+	s.mu.Unlock()
+}
+
+// FindBrokenLinks collect failed site pages
+// with all source pages linking to them
+func (s *Site) FindBrokenLinks() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Broken = nil
+	for url, page := range s.HashMap {
+		if page.State != Failed {
+			continue
+		}
+
+		link := &BrokenLink{Url: url, Error: page.Error}
+		if page.Response != nil {
+			link.Status = page.Response.Status
+		}
+
+		link.Sources = s.pageSources(url)
+		s.Broken = append(s.Broken, link)
+	}
+
+	sort.Slice(s.Broken, func(i, j int) bool {
+		return s.Broken[i].Url < s.Broken[j].Url
+	})
+}
+
+// Anchors represent anchor texts of site links
+// indexed by source page url and target page key
+type Anchors struct {
+	texts      map[[2]string]string // anchor text by source page url and target page key
+	normalizer *Normalizer          // normalizer of target pages keys
+}
+
+// Anchors threadsafe return index of anchor texts of every site link,
+// first link from source page to target page wins
+func (s *Site) Anchors() Anchors {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	anchors := Anchors{texts: make(map[[2]string]string), normalizer: s.normalizer}
+	for key, sources := range s.Sources {
+		for _, source := range sources {
+			link := [2]string{source.Url, key}
+			if _, ok := anchors.texts[link]; !ok {
+				anchors.texts[link] = source.Text
+			}
+		}
+	}
+	return anchors
+}
+
+// Text return anchor text of the link from given source page url to target url
+func (a Anchors) Text(source, target string) string {
+	return a.texts[[2]string{source, a.normalizer.KeyString(target)}]
+}
+
+// PageSources threadsafe return sorted source pages linking to given page
+func (s *Site) PageSources(url string) []Source {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pageSources(url)
+}
+
+// pageSources return sorted source pages linking to given page,
+// links to page can differ by its url form
+func (s *Site) pageSources(url string) []Source {
+	sources := append([]Source{}, s.Sources[s.normalizer.KeyString(url)]...)
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].Url < sources[j].Url
+	})
+	return sources
+}
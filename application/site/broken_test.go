@@ -0,0 +1,83 @@
+package site
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestSite_FindBrokenLinks(t *testing.T) {
+	site := getTestSite()
+	blog := site.HashMap["https://monzo.com/blog"]
+	blog.State = Failed
+	blog.Error = "server responded 404 Not Found"
+	blog.Response = &Response{Status: 404}
+	site.HashMap["https://monzo.com/blog/haha"].State = Crawled
+
+	site.AddSource("https://monzo.com/blog/", Source{Url: "https://monzo.com/blog/haha", Text: "Blog"})
+	site.AddSource("https://monzo.com/blog", Source{Url: "https://monzo.com", Text: "Our blog"})
+	site.AddSource("https://monzo.com/blog/haha", Source{Url: "https://monzo.com/blog", Text: "Haha"})
+
+	site.FindBrokenLinks()
+
+	want := BrokenLinks{{
+		Url:    "https://monzo.com/blog",
+		Status: 404,
+		Error:  "server responded 404 Not Found",
+		Sources: []Source{
+			{Url: "https://monzo.com", Text: "Our blog"},
+			{Url: "https://monzo.com/blog/haha", Text: "Blog"},
+		},
+	}}
+	if !reflect.DeepEqual(site.Broken, want) {
+		t.Errorf("Site.FindBrokenLinks() = %v, want %v", site.Broken, want)
+	}
+}
+
+func TestSite_Anchors(t *testing.T) {
+	site := getTestSite()
+	site.AddSource("https://monzo.com/blog/", Source{Url: "https://monzo.com", Text: "Blog"})
+	site.AddSource("https://monzo.com/blog", Source{Url: "https://monzo.com", Text: "Our blog"})
+	site.AddSource("https://monzo.com/blog/haha", Source{Url: "https://monzo.com/blog", Text: "Haha"})
+
+	anchors := site.Anchors()
+
+	tests := []struct {
+		name   string
+		source string
+		target string
+		want   string
+	}{
+		{"link", "https://monzo.com/blog", "https://monzo.com/blog/haha", "Haha"},
+		{"firstLinkWins", "https://monzo.com", "https://monzo.com/blog", "Blog"},
+		{"normalizedTarget", "https://monzo.com", "https://monzo.com/blog/index.html", "Blog"},
+		{"noLink", "https://monzo.com/blog/haha", "https://monzo.com/blog", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := anchors.Text(tt.source, tt.target); got != tt.want {
+				t.Errorf("Anchors.Text() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrokenLinksReport_MarshalXML(t *testing.T) {
+	site := getTestSite()
+	site.Broken = BrokenLinks{{
+		Url:     "https://monzo.com/blog",
+		Error:   "connection refused",
+		Sources: []Source{{Url: "https://monzo.com", Text: "Blog"}},
+	}}
+
+	got, err := xml.Marshal(NewBrokenLinksReport(site))
+	if err != nil {
+		t.Errorf("xml.Marshal() error = %v", err)
+		return
+	}
+	want := `<broken_links><url>https://monzo.com</url><total>1</total><links><link><url>https://monzo.com/blog</url>` +
+		`<error>connection refused</error><sources><source><url>https://monzo.com</url><text>Blog</text></source></sources></link></links></broken_links>`
+	if string(got) != want {
+		t.Errorf("xml.Marshal() = %s, want %s", got, want)
+	}
+}
@@ -7,7 +7,8 @@ import (
 )
 
 // PagesHashMap represent Pages Hash Map structure type
-type PagesHashMap map[string][]string
+// with page url as a key and crawled page as a value
+type PagesHashMap map[string]*Page
 
 // MarshalJSON correct formatted JSON marshaling
 // for Page Hash Map structure type
@@ -24,7 +25,7 @@ func (p PagesHashMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 
 	err := e.EncodeElement(struct {
 		XMLName xml.Name    `xml:"page"`
-		Pages   *[]hashPage `xml:"pages"`
+		Pages   *[]HashPage `xml:"pages"`
 	}{
 		Pages: pages}, start)
 	if err != nil {
@@ -33,29 +34,104 @@ func (p PagesHashMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	return nil
 }
 
-// hashPage represent PagesHashMap formatter for XML and JSON marshaling
-type hashPage struct {
+// UnmarshalJSON correct formatted JSON unmarshaling
+// for Page Hash Map structure type
+func (p *PagesHashMap) UnmarshalJSON(data []byte) error {
+	var pages []HashPage
+	if err := json.Unmarshal(data, &pages); err != nil {
+		return err
+	}
+	return p.hashPagesToMap(pages)
+}
+
+// UnmarshalXML correct formatted XML unmarshaling
+// for Page Hash Map structure type
+func (p *PagesHashMap) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var hash struct {
+		Pages []HashPage `xml:"page"`
+	}
+	if err := d.DecodeElement(&hash, &start); err != nil {
+		return err
+	}
+	return p.hashPagesToMap(hash.Pages)
+}
+
+// HashPage represent PagesHashMap formatter for XML and JSON marshaling
+type HashPage struct {
 	XMLName    xml.Name  `json:"-" xml:"page"`
-	Url        string    `json:"url "xml:"url"`
+	Url        string    `json:"url" xml:"url"`
+	Depth      int       `json:"depth" xml:"depth"`
+	State      PageState `json:"state" xml:"state"`
+	Attempts   int       `json:"attempts,omitempty" xml:"attempts,omitempty"`
+	Error      string    `json:"error,omitempty" xml:"error,omitempty"`
+	Response   *Response `json:"response,omitempty" xml:"response,omitempty"`
 	TotalLinks int       `json:"total_links" xml:"total_links"`
 	Links      *[]string `json:"links" xml:"links>url,omitempty"`
 }
 
-// mapToHashPages create slice of hashPage from PagesHashMap
-func (p PagesHashMap) mapToHashPages() *[]hashPage {
-	var pages []hashPage
-	for url, links := range p {
-		page := hashPage{Url: url}
-		var lks []string
-		for _, link := range links {
-			lks = append(lks, link)
-		}
-		page.TotalLinks = len(lks)
-		page.Links = &lks
-		pages = append(pages, page)
+// NewHashPage create hash map output representation of given page
+func NewHashPage(page *Page) HashPage {
+	hp := HashPage{Url: page.Url.String(), Depth: page.Depth, State: page.State, Attempts: page.Attempts, Error: page.Error, Response: page.Response}
+	var lks []string
+	for _, link := range page.Links {
+		lks = append(lks, link.Url.String())
+	}
+	hp.TotalLinks = len(lks)
+	hp.Links = &lks
+	return hp
+}
+
+// mapToHashPages create slice of HashPage from PagesHashMap
+func (p PagesHashMap) mapToHashPages() *[]HashPage {
+	var pages []HashPage
+	for url, page := range p {
+		hp := NewHashPage(page)
+		hp.Url = url
+		pages = append(pages, hp)
 	}
 	sort.Slice(pages, func(i, j int) bool {
-		return len(pages[i].Url) < len(pages[j].Url)
+		if len(pages[i].Url) != len(pages[j].Url) {
+			return len(pages[i].Url) < len(pages[j].Url)
+		}
+		return pages[i].Url < pages[j].Url
 	})
 	return &pages
 }
+
+// hashPagesToMap fill PagesHashMap from slice of HashPage, links
+// to map pages are resolved to them, other links are linked pages
+func (p *PagesHashMap) hashPagesToMap(pages []HashPage) error {
+	hash := make(PagesHashMap, len(pages))
+	for _, hp := range pages {
+		url, err := parseUrl(hp.Url)
+		if err != nil {
+			return err
+		}
+		page := NewPage(url)
+		page.Depth, page.State, page.Attempts, page.Error, page.Response = hp.Depth, hp.State, hp.Attempts, hp.Error, hp.Response
+		page.TotalLinks = hp.TotalLinks
+		hash[hp.Url] = page
+	}
+
+	for _, hp := range pages {
+		if hp.Links == nil {
+			continue
+		}
+		page := hash[hp.Url]
+		for _, link := range *hp.Links {
+			linked, ok := hash[link]
+			if !ok {
+				url, err := parseUrl(link)
+				if err != nil {
+					return err
+				}
+				linked = NewPage(url)
+				linked.Depth = page.Depth + 1
+			}
+			page.Links = append(page.Links, linked)
+		}
+	}
+
+	*p = hash
+	return nil
+}
@@ -0,0 +1,30 @@
+package site
+
+// Link represent directed link between site pages
+type Link struct {
+	Source *Page  // page containing the link
+	Target *Page  // linked page, nil if it is not in the site
+	Url    string // linked page Url
+}
+
+// Links return every link of given site pages with linked
+// page found among them by page key
+func Links(pages []*Page) []Link {
+	// index of pages by key
+	index := make(map[string]*Page, len(pages))
+	for _, page := range pages {
+		index[page.Key()] = page
+	}
+
+	var links []Link
+	for _, page := range pages {
+		for _, link := range page.Links {
+			links = append(links, Link{
+				Source: page,
+				Target: index[link.Key()],
+				Url:    link.Url.String(),
+			})
+		}
+	}
+	return links
+}
@@ -0,0 +1,37 @@
+package site
+
+import (
+	"testing"
+)
+
+func TestLinks(t *testing.T) {
+	site := getTestSite()
+	blog := site.HashMap["https://monzo.com/blog"]
+	home, _ := blog.AddSubPage("/")
+	blog.AddSubPage("/external/page")
+
+	links := Links(site.Pages())
+	tests := []struct {
+		name       string
+		source     string
+		url        string
+		wantTarget *Page
+	}{
+		{"child", "https://monzo.com", "https://monzo.com/blog", blog},
+		{"grandChild", "https://monzo.com/blog", "https://monzo.com/blog/haha", site.HashMap["https://monzo.com/blog/haha"]},
+		{"trailingSlash", "https://monzo.com/blog", home.Url.String(), site.PageTree},
+		{"notInSite", "https://monzo.com/blog", "https://monzo.com/external/page", nil},
+	}
+	if len(links) != len(tests) {
+		t.Fatalf("Links() count = %d, want %d", len(links), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := links[i]
+			if link.Source.Url.String() != tt.source || link.Url != tt.url || link.Target != tt.wantTarget {
+				t.Errorf("Links() = %s -> %s (%v), want %s -> %s (%v)",
+					link.Source.Url, link.Url, link.Target, tt.source, tt.url, tt.wantTarget)
+			}
+		})
+	}
+}
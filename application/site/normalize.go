@@ -0,0 +1,191 @@
+package site
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultIndexFiles is default directory index file names
+var DefaultIndexFiles = []string{"index.html", "index.htm", "index.php"}
+
+// DefaultNormalizer is normalizer of pages without configured one
+var DefaultNormalizer = &Normalizer{IgnoreTrailingSlash: true, IndexFiles: DefaultIndexFiles}
+
+// defaultPorts is default ports of url schemes
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Normalizer represent canonical url normalization. Every site url
+// is normalized: scheme and host are lowercased, default port,
+// fragment and dot segments are removed, percent-encoding is
+// normalized and query string is handled by query policy.
+// Normalized urls with same key are the same page.
+type Normalizer struct {
+	IgnoreTrailingSlash bool        // urls differing by trailing slash of path are the same page
+	IndexFiles          []string    // directory index file names, urls with and without them are the same page
+	IgnoreScheme        bool        // http and https urls are the same page
+	Query               QueryPolicy // query string handling policy
+}
+
+// Normalize return canonical form of given url
+func (n *Normalizer) Normalize(u *Url) *Url {
+	if n == nil {
+		n = DefaultNormalizer
+	}
+
+	normalized := *u.URL
+	normalized.Scheme = strings.ToLower(normalized.Scheme)
+	normalized.Host = strings.ToLower(normalized.Host)
+	if port := normalized.Port(); port != "" && port == defaultPorts[normalized.Scheme] {
+		normalized.Host = strings.TrimSuffix(normalized.Host, ":"+port)
+	}
+	normalized.Fragment, normalized.RawFragment = "", ""
+
+	// path is normalized in escaped form to keep reserved characters escaped
+	escaped := removeDotSegments(normalizeEscapes(normalized.EscapedPath()))
+	if path, err := url.PathUnescape(escaped); err == nil {
+		normalized.Path, normalized.RawPath = path, ""
+		if normalized.EscapedPath() != escaped {
+			normalized.RawPath = escaped
+		}
+	}
+	normalized.RawQuery = n.Query.apply(normalizeEscapes(normalized.RawQuery))
+	normalized.ForceQuery = false
+
+	return &Url{&normalized}
+}
+
+// Key return key of given url, which is the same for all urls of the page
+func (n *Normalizer) Key(u *Url) string {
+	if n == nil {
+		n = DefaultNormalizer
+	}
+
+	normalized := n.Normalize(u)
+	path := normalized.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	for _, index := range n.IndexFiles {
+		if strings.HasSuffix(path, "/"+index) {
+			path = strings.TrimSuffix(path, index)
+			break
+		}
+	}
+	if n.IgnoreTrailingSlash {
+		path = strings.TrimSuffix(path, "/")
+	}
+
+	key := normalized.Scheme + "://" + normalized.Host + path
+	if n.IgnoreScheme && isWebScheme(normalized.Scheme) {
+		key = "//" + normalized.Host + path
+	}
+	if normalized.RawQuery != "" {
+		key += "?" + normalized.RawQuery
+	}
+	return key
+}
+
+// KeyString return key of given string url,
+// unparsable url is key itself
+func (n *Normalizer) KeyString(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return rawUrl
+	}
+	return n.Key(&Url{u})
+}
+
+// ignoreScheme check if normalizer treats http and https urls as the same page
+func (n *Normalizer) ignoreScheme() bool {
+	if n == nil {
+		n = DefaultNormalizer
+	}
+	return n.IgnoreScheme
+}
+
+// query return query policy of normalizer
+func (n *Normalizer) query() QueryPolicy {
+	if n == nil {
+		n = DefaultNormalizer
+	}
+	return n.Query
+}
+
+// removeDotSegments remove "." and ".." segments of given url path
+func removeDotSegments(path string) string {
+	if !strings.Contains(path, ".") {
+		return path
+	}
+
+	var segments []string
+	parts := strings.Split(path, "/")
+	for i, segment := range parts {
+		last := i == len(parts)-1
+		switch segment {
+		case ".":
+			if last {
+				segments = append(segments, "")
+			}
+		case "..":
+			if len(segments) > 1 {
+				segments = segments[:len(segments)-1]
+			}
+			if last {
+				segments = append(segments, "")
+			}
+		default:
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// normalizeEscapes decode percent-encoded unreserved
+// characters and uppercase hex digits of other escapes
+func normalizeEscapes(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			b.WriteByte(s[i])
+			continue
+		}
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteString("%" + strings.ToUpper(s[i+1:i+3]))
+		}
+		i += 2
+	}
+	return b.String()
+}
+
+// isUnreserved check if given character is unreserved url character
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// isHex check if given character is hex digit
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// unhex return value of given hex digit
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
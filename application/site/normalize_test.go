@@ -0,0 +1,76 @@
+package site
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestNormalizer_Normalize(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"canonical", "https://monzo.com/blog", "https://monzo.com/blog"},
+		{"hostCase", "HTTPS://Monzo.COM/Blog", "https://monzo.com/Blog"},
+		{"defaultHttpsPort", "https://monzo.com:443/blog", "https://monzo.com/blog"},
+		{"defaultHttpPort", "http://monzo.com:80/blog", "http://monzo.com/blog"},
+		{"otherPort", "https://monzo.com:8443/blog", "https://monzo.com:8443/blog"},
+		{"fragment", "https://monzo.com/blog#comments", "https://monzo.com/blog"},
+		{"fragmentAfterSlash", "https://monzo.com/blog/#comments", "https://monzo.com/blog/"},
+		{"dotSegments", "https://monzo.com/blog/./2020/../post", "https://monzo.com/blog/post"},
+		{"dotSegmentsAboveRoot", "https://monzo.com/../../blog", "https://monzo.com/blog"},
+		{"trailingDotSegment", "https://monzo.com/blog/post/..", "https://monzo.com/blog/"},
+		{"unreservedEscape", "https://monzo.com/%7Euser/%61bout", "https://monzo.com/~user/about"},
+		{"reservedEscapeCase", "https://monzo.com/a%2fb", "https://monzo.com/a%2Fb"},
+		{"queryEscapeCase", "https://monzo.com/search?q=a%2fb%7e", "https://monzo.com/search?q=a%2Fb~"},
+		{"trailingSlashKept", "https://monzo.com/blog/", "https://monzo.com/blog/"},
+		{"indexFileKept", "https://monzo.com/blog/index.html", "https://monzo.com/blog/index.html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultNormalizer.Normalize(getTestUrl(tt.url)).String(); got != tt.want {
+				t.Errorf("Normalizer.Normalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizer_Key(t *testing.T) {
+	strict := &Normalizer{}
+	tests := []struct {
+		name       string
+		normalizer *Normalizer
+		url        string
+		want       string
+	}{
+		{"root", DefaultNormalizer, "https://monzo.com", "https://monzo.com"},
+		{"rootSlash", DefaultNormalizer, "https://monzo.com/", "https://monzo.com"},
+		{"trailingSlash", DefaultNormalizer, "https://monzo.com/blog/", "https://monzo.com/blog"},
+		{"indexFile", DefaultNormalizer, "https://monzo.com/blog/index.html", "https://monzo.com/blog"},
+		{"indexPhp", DefaultNormalizer, "https://monzo.com/index.php", "https://monzo.com"},
+		{"notIndexFile", DefaultNormalizer, "https://monzo.com/blog/myindex.html", "https://monzo.com/blog/myindex.html"},
+		{"hostCaseAndPort", DefaultNormalizer, "HTTPS://MONZO.com:443/blog#top", "https://monzo.com/blog"},
+		{"query", DefaultNormalizer, "https://monzo.com/blog/?page=2", "https://monzo.com/blog?page=2"},
+		{"nilNormalizer", nil, "https://monzo.com/blog/", "https://monzo.com/blog"},
+		{"strictRoot", strict, "https://monzo.com", "https://monzo.com/"},
+		{"strictTrailingSlash", strict, "https://monzo.com/blog/", "https://monzo.com/blog/"},
+		{"strictNoSlash", strict, "https://monzo.com/blog", "https://monzo.com/blog"},
+		{"strictIndexFile", strict, "https://monzo.com/blog/index.html", "https://monzo.com/blog/index.html"},
+		{"anyScheme", &Normalizer{IgnoreScheme: true}, "http://monzo.com/blog", "//monzo.com/blog"},
+		{"anySchemeNotWeb", &Normalizer{IgnoreScheme: true}, "ftp://monzo.com/blog", "ftp://monzo.com/blog"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.normalizer.Key(getTestUrl(tt.url)); got != tt.want {
+				t.Errorf("Normalizer.Key() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// getTestUrl return Url parsed from given string
+func getTestUrl(rawUrl string) *Url {
+	u, _ := url.Parse(rawUrl)
+	return &Url{u}
+}
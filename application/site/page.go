@@ -14,16 +14,24 @@ var (
 	errExternalLink    = errors.New("link is external")
 	errAlreadyInParent = errors.New("link already in parent slice")
 	errEmailProtected  = errors.New("link is email-protected")
+	errRelativeLink    = errors.New("link is relative to page path")
 	errInvalidScheme   = errors.New("different Url Scheme from parent")
 )
 
 // Page represent web-site page structure with own URL
 // and slice of the links - pointers to other pages
 type Page struct {
-	Url        *Url          `json:"url" xml:"url"`                              // Page Url
-	TotalLinks int           `json:"total,omitempty" xml:"total,omitempty"`      // Total valid links in page
-	Links      []*Page       `json:"links,omitempty" xml:"links>page,omitempty"` // Slice of valid pages links in current Page
-	Logger     *logrus.Entry `json:"-" xml:"-"`                                  // Page logger with necessary fields
+	Url        *Url          `json:"url" xml:"url"`                               // Page Url
+	Depth      int           `json:"depth,omitempty" xml:"depth,omitempty"`       // Distance from site entry page
+	State      PageState     `json:"state,omitempty" xml:"state,omitempty"`       // Page crawling state
+	Attempts   int           `json:"attempts,omitempty" xml:"attempts,omitempty"` // Number of page fetch attempts
+	Error      string        `json:"error,omitempty" xml:"error,omitempty"`       // Final page fetch error
+	Response   *Response     `json:"response,omitempty" xml:"response,omitempty"` // Page fetching result
+	TotalLinks int           `json:"total,omitempty" xml:"total,omitempty"`       // Total valid links in page
+	Links      []*Page       `json:"links,omitempty" xml:"links>page,omitempty"`  // Slice of valid pages links in current Page
+	Logger     *logrus.Entry `json:"-" xml:"-"`                                   // Page logger with necessary fields
+	normalizer *Normalizer   `json:"-" xml:"-"`                                   // Page urls normalizer, nil - default one
+	scope      *Scope        `json:"-" xml:"-"`                                   // Site hosts scope, nil - only page host
 }
 
 // NewPage create new Page structure instance
@@ -32,6 +40,13 @@ func NewPage(url *Url) *Page {
 	return &Page{Url: url, Logger: logger}
 }
 
+// setNormalizer set normalizer of page urls and normalize page Url
+func (p *Page) setNormalizer(n *Normalizer) {
+	p.normalizer = n
+	p.Url = n.Normalize(p.Url)
+	p.Logger = logrus.WithField("page", p.Url.String())
+}
+
 // AddSubPage validate and create Child Page of current Parent page
 // Return Child page after successes result
 func (p *Page) AddSubPage(link string) (*Page, error) {
@@ -40,11 +55,12 @@ func (p *Page) AddSubPage(link string) (*Page, error) {
 		return nil, err
 	}
 
-	// get Url from string
+	// get canonical Url from string
 	url, err := p.Url.ParseUrl(link)
 	if err != nil {
 		return nil, errParsedLink
 	}
+	url = p.normalizer.Normalize(url)
 
 	// validate received Url
 	if err := p.validateUrl(url); err != nil {
@@ -54,8 +70,11 @@ func (p *Page) AddSubPage(link string) (*Page, error) {
 	// increase parent totalPage counter
 	p.TotalLinks++
 
-	// create new page
+	// create new page one level deeper than parent
 	page := NewPage(url)
+	page.Depth = p.Depth + 1
+	page.normalizer = p.normalizer
+	page.scope = p.scope
 
 	// add child page to parent page tree
 	p.Links = append(p.Links, page)
@@ -66,23 +85,23 @@ func (p *Page) AddSubPage(link string) (*Page, error) {
 // validateUrl validate if given Url is valid
 // with to Child Page of current Parent Page
 func (p Page) validateUrl(url *Url) error {
-	// valid if link has querystring
-	if len(url.Query()) > 0 {
+	// valid if link has querystring and query policy drops them
+	if p.normalizer.query().Mode == QueryDrop && len(url.Query()) > 0 {
 		return errQueryLink
 	}
 
-	// additional host validation
-	if url.Host != p.Url.Host {
+	// check if url host belongs to site hosts scope
+	if !p.scope.Contains(p.Url, url) {
 		return errExternalLink
 	}
 
-	// remove duplicate http & https
-	if url.Scheme != p.Url.Scheme {
+	// remove duplicate http & https, if they are not the same site
+	if url.Scheme != p.Url.Scheme && !(p.normalizer.ignoreScheme() && isWebScheme(url.Scheme)) {
 		return errInvalidScheme
 	}
 
 	// check if parent page already have this sub page
-	contain := p.inPage(url.String())
+	contain := p.inPage(p.normalizer.Key(url))
 	if contain {
 		return errAlreadyInParent
 	}
@@ -97,19 +116,58 @@ func (p Page) validateLink(link string) error {
 		return errEmailProtected
 	}
 
-	// check if given link belong to current site
-	if !strings.HasPrefix(link, "/") && !strings.Contains(link, p.Url.Host) {
+	// links relative to site root belong to current site
+	if strings.HasPrefix(link, "/") && !strings.HasPrefix(link, "//") {
+		return nil
+	}
+
+	// links relative to page path are not followed
+	if !hasScheme(link) && !strings.HasPrefix(link, "//") {
+		return errRelativeLink
+	}
+
+	// check if host of given link belong to current site
+	url, err := p.Url.ParseUrl(link)
+	if err != nil {
+		return errParsedLink
+	}
+	if !p.scope.Contains(p.Url, url) {
 		return errExternalLink
 	}
 	return nil
 }
 
-// inPage checks if Page contain given Child Page
-func (p Page) inPage(s string) bool {
+// hasScheme check if given link starts with url scheme
+func hasScheme(link string) bool {
+	for i, c := range link {
+		switch {
+		case 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '+' || c == '-' || c == '.'):
+		case i > 0 && c == ':':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// isWebScheme check if given url scheme is http or https
+func isWebScheme(scheme string) bool {
+	return scheme == "http" || scheme == "https"
+}
+
+// inPage checks if Page contain Child Page with given key
+func (p Page) inPage(key string) bool {
 	for _, v := range p.Links {
-		if s == v.Url.String() || v.Url.String()+"/" == s {
+		if key == p.normalizer.Key(v.Url) {
 			return true
 		}
 	}
 	return false
 }
+
+// Key return page key, pages with the same key are the same page
+func (p *Page) Key() string {
+	return p.normalizer.Key(p.Url)
+}
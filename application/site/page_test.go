@@ -72,6 +72,14 @@ func TestPage_validateLink(t *testing.T) {
 		{"invalidPath", args{"about/contact"}, true},
 		{"externalUrl", args{"https://twitter.com/lala"}, true},
 		{"email-protected", args{"https://monzo.com/faq/1/email-protection"}, true},
+		{"hostInQuery", args{"https://evil.com/?x=monzo.com"}, true},
+		{"hostInQueryNoScheme", args{"evil.com/?x=monzo.com"}, true},
+		{"hostInPath", args{"https://evil.com/monzo.com"}, true},
+		{"hostCase", args{"https://MONZO.com/faq"}, false},
+		{"protocolRelative", args{"//monzo.com/faq"}, false},
+		{"protocolRelativeExternal", args{"//evil.com/monzo.com"}, true},
+		{"mailto", args{"mailto:help@monzo.com"}, true},
+		{"anchor", args{"#top"}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -123,6 +131,7 @@ func TestPage_AddSubPage(t *testing.T) {
 	validLink := "https://monzo.com/news"
 	validUrl, _ := ParseRequestURI(validLink)
 	validPage := NewPage(validUrl)
+	validPage.Depth = 1
 
 	type args struct {
 		link string
@@ -151,6 +160,57 @@ func TestPage_AddSubPage(t *testing.T) {
 	}
 }
 
+func TestPage_AddSubPage_query(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  QueryPolicy
+		link    string
+		want    string
+		wantErr bool
+	}{
+		{"drop", QueryPolicy{Mode: QueryDrop}, "/news?page=2", "", true},
+		{"keep", QueryPolicy{Mode: QueryKeep}, "/news?page=2&a=1", "https://monzo.com/news?a=1&page=2", false},
+		{"strip", QueryPolicy{Mode: QueryStrip}, "/news?utm_source=x", "https://monzo.com/news", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := getTestPage()
+			page.normalizer = &Normalizer{Query: tt.policy}
+			got, err := page.AddSubPage(tt.link)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Page.AddSubPage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.Url.String() != tt.want {
+				t.Errorf("Page.AddSubPage() url = %v, want %v", got.Url, tt.want)
+			}
+		})
+	}
+}
+
+func TestPage_AddSubPage_scheme(t *testing.T) {
+	tests := []struct {
+		name       string
+		normalizer *Normalizer
+		link       string
+		wantErr    bool
+	}{
+		{"otherScheme", nil, "http://monzo.com/news", true},
+		{"anyScheme", &Normalizer{IgnoreScheme: true}, "http://monzo.com/news", false},
+		{"anySchemeDuplicate", &Normalizer{IgnoreScheme: true}, "http://monzo.com/blog", true},
+		{"anySchemeNotWeb", &Normalizer{IgnoreScheme: true}, "ftp://monzo.com/news", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := getTestPage()
+			page.normalizer = tt.normalizer
+			if _, err := page.AddSubPage(tt.link); (err != nil) != tt.wantErr {
+				t.Errorf("Page.AddSubPage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func getTestPage() (page *Page) {
 	url, _ := ParseRequestURI("https://monzo.com")
 	subUrl, _ := url.ParseUrl("/blog")
@@ -0,0 +1,121 @@
+package site
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// QueryMode represent query string handling mode
+type QueryMode int
+
+// available QueryMode constants
+const (
+	QueryDrop  QueryMode = iota // links with query string are rejected
+	QueryKeep                   // all query parameters are kept
+	QueryAllow                  // only allowlisted query parameters are kept
+	QueryStrip                  // tracking query parameters are removed
+	unsupportedQueryMode
+)
+
+// queryModes is slice of query mode string representations
+var queryModes = [...]string{
+	QueryDrop:  "drop",
+	QueryKeep:  "keep",
+	QueryAllow: "allow",
+	QueryStrip: "strip",
+}
+
+// String return query mode enum as a string
+func (m QueryMode) String() string {
+	return queryModes[m]
+}
+
+// ParseQueryMode return new QueryMode enum from given string
+func ParseQueryMode(s string) (QueryMode, error) {
+	for i, r := range queryModes {
+		if s == r {
+			return QueryMode(i), nil
+		}
+	}
+	return unsupportedQueryMode, fmt.Errorf("invalid query mode value %q", s)
+}
+
+// TrackingParams is known tracking query parameters removed in strip mode,
+// parameters ending with * match any parameter with given prefix
+var TrackingParams = []string{
+	"utm_*", "gclid", "gclsrc", "dclid", "fbclid", "msclkid", "yclid",
+	"mc_cid", "mc_eid", "_ga", "_gl", "_hsenc", "_hsmi", "igshid", "ref_src",
+}
+
+// QueryPolicy represent handling of url query strings.
+// Kept query parameters are sorted by name, so urls
+// differing by parameters order are the same page.
+type QueryPolicy struct {
+	Mode        QueryMode // query string handling mode
+	Params      []string  // allowlisted parameters in allow mode, additionally removed ones in strip mode
+	MaxVariants int       // maximum distinct query variants of one path, 0 - unlimited
+}
+
+// apply return given escaped query string with parameters
+// filtered by policy mode and sorted by name
+func (q QueryPolicy) apply(query string) string {
+	if query == "" || q.Mode == QueryDrop {
+		return query
+	}
+
+	type param struct {
+		name, raw string
+	}
+	var params []param
+	for _, raw := range strings.Split(query, "&") {
+		if raw == "" {
+			continue
+		}
+		name := strings.SplitN(raw, "=", 2)[0]
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if q.keep(name) {
+			params = append(params, param{name, raw})
+		}
+	}
+
+	// values of repeated parameter keep their order
+	sort.SliceStable(params, func(i, j int) bool {
+		return params[i].name < params[j].name
+	})
+	raws := make([]string, len(params))
+	for i, p := range params {
+		raws[i] = p.raw
+	}
+	return strings.Join(raws, "&")
+}
+
+// keep check if query parameter with given name is kept by policy
+func (q QueryPolicy) keep(name string) bool {
+	switch q.Mode {
+	case QueryAllow:
+		return matchParam(q.Params, name)
+	case QueryStrip:
+		return !matchParam(TrackingParams, name) && !matchParam(q.Params, name)
+	default:
+		return true
+	}
+}
+
+// matchParam check if given parameter name matches one of patterns,
+// patterns ending with * match any name with given prefix
+func matchParam(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if prefix := strings.TrimSuffix(pattern, "*"); prefix != pattern {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
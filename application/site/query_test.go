@@ -0,0 +1,113 @@
+package site
+
+import "testing"
+
+func TestParseQueryMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    QueryMode
+		wantErr bool
+	}{
+		{"drop", "drop", QueryDrop, false},
+		{"keep", "keep", QueryKeep, false},
+		{"allow", "allow", QueryAllow, false},
+		{"strip", "strip", QueryStrip, false},
+		{"unsupported", "all", unsupportedQueryMode, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQueryMode(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseQueryMode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseQueryMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizer_Normalize_query(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy QueryPolicy
+		url    string
+		want   string
+	}{
+		{"dropUntouched", QueryPolicy{Mode: QueryDrop}, "https://monzo.com/blog?page=2&a=1", "https://monzo.com/blog?page=2&a=1"},
+		{"keepSorted", QueryPolicy{Mode: QueryKeep}, "https://monzo.com/blog?page=2&a=1", "https://monzo.com/blog?a=1&page=2"},
+		{"keepRepeatedOrder", QueryPolicy{Mode: QueryKeep}, "https://monzo.com/blog?tag=b&a=1&tag=a", "https://monzo.com/blog?a=1&tag=b&tag=a"},
+		{"keepEmptyParams", QueryPolicy{Mode: QueryKeep}, "https://monzo.com/blog?&page=2&&", "https://monzo.com/blog?page=2"},
+		{"keepEmptyQuery", QueryPolicy{Mode: QueryKeep}, "https://monzo.com/blog?", "https://monzo.com/blog"},
+		{"allow", QueryPolicy{Mode: QueryAllow, Params: []string{"page"}}, "https://monzo.com/blog?sort=new&page=2", "https://monzo.com/blog?page=2"},
+		{"allowPrefix", QueryPolicy{Mode: QueryAllow, Params: []string{"f_*"}}, "https://monzo.com/shop?f_size=m&sort=new&f_color=red", "https://monzo.com/shop?f_color=red&f_size=m"},
+		{"allowNone", QueryPolicy{Mode: QueryAllow, Params: []string{"page"}}, "https://monzo.com/blog?sort=new", "https://monzo.com/blog"},
+		{"stripTracking", QueryPolicy{Mode: QueryStrip}, "https://monzo.com/blog?utm_source=x&page=2&gclid=1&utm_medium=y", "https://monzo.com/blog?page=2"},
+		{"stripExtra", QueryPolicy{Mode: QueryStrip, Params: []string{"session"}}, "https://monzo.com/blog?session=1&page=2", "https://monzo.com/blog?page=2"},
+		{"stripEscapedName", QueryPolicy{Mode: QueryStrip}, "https://monzo.com/blog?utm%5Fsource=x", "https://monzo.com/blog"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Normalizer{Query: tt.policy}
+			if got := n.Normalize(getTestUrl(tt.url)).String(); got != tt.want {
+				t.Errorf("Normalizer.Normalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSite_AddPageToSite_queryVariants(t *testing.T) {
+	s := getTestSite()
+	s.SetNormalizer(&Normalizer{Query: QueryPolicy{Mode: QueryKeep, MaxVariants: 2}})
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{"first", "https://monzo.com/blog?page=2", false},
+		{"second", "https://monzo.com/blog?page=3", false},
+		{"sameVariant", "https://monzo.com/blog?page=3", true},
+		{"third", "https://monzo.com/blog?page=4", true},
+		{"otherPath", "https://monzo.com/news?page=2", false},
+		{"noQuery", "https://monzo.com/blog/haha/post", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.AddPageToSite(getTestPageFromString(tt.url)); (err != nil) != tt.wantErr {
+				t.Errorf("Site.AddPageToSite() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if len(s.Limits) != 1 || s.Limits[0] != LimitVariants {
+		t.Errorf("Site.AddPageToSite() limits = %v, want %v", s.Limits, Limits{LimitVariants})
+	}
+}
+
+func TestSite_DeletePageFromSite_queryVariants(t *testing.T) {
+	s := getTestSite()
+	s.SetNormalizer(&Normalizer{Query: QueryPolicy{Mode: QueryKeep, MaxVariants: 2}})
+
+	for _, url := range []string{"https://monzo.com/blog?page=2", "https://monzo.com/blog?page=3"} {
+		if err := s.AddPageToSite(getTestPageFromString(url)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// deleted variant frees place for another one
+	s.DeletePageFromSite("https://monzo.com/blog?page=3")
+	if err := s.AddPageToSite(getTestPageFromString("https://monzo.com/blog?page=4")); err != nil {
+		t.Errorf("Site.AddPageToSite() error = %v, want nil", err)
+	}
+	if err := s.AddPageToSite(getTestPageFromString("https://monzo.com/blog?page=5")); err == nil {
+		t.Errorf("Site.AddPageToSite() error = nil, want variants limit error")
+	}
+
+	// deleting not added page does not change variants count
+	s.DeletePageFromSite("https://monzo.com/blog?page=6")
+	if err := s.AddPageToSite(getTestPageFromString("https://monzo.com/blog?page=7")); err == nil {
+		t.Errorf("Site.AddPageToSite() error = nil, want variants limit error")
+	}
+}
@@ -0,0 +1,35 @@
+package site
+
+// Response represent fetching result of the page:
+// final response metadata and redirects chain to it
+type Response struct {
+	Status        int         `json:"status" xml:"status"`                                   // final response status code
+	FinalUrl      string      `json:"final_url" xml:"final_url"`                             // page Url after redirects
+	ContentType   string      `json:"content_type,omitempty" xml:"content_type,omitempty"`   // response Content-Type header
+	ContentLength int64       `json:"content_length" xml:"content_length"`                   // response body length, -1 if unknown
+	LastModified  string      `json:"last_modified,omitempty" xml:"last_modified,omitempty"` // Last-Modified header in W3C Datetime format
+	ResponseTime  int64       `json:"response_time_ms" xml:"response_time_ms"`               // total fetching time in milliseconds
+	Redirects     []*Redirect `json:"redirects,omitempty" xml:"redirect,omitempty"`          // redirects chain to final Url
+}
+
+// Redirect represent one redirect hop of page fetching
+type Redirect struct {
+	Url      string `json:"url" xml:"url"`           // requested Url
+	Status   int    `json:"status" xml:"status"`     // redirect status code
+	Location string `json:"location" xml:"location"` // absolute Url of Location header
+}
+
+// Loop check if redirects chain ends with
+// redirect to already requested Url
+func (r *Response) Loop() bool {
+	if len(r.Redirects) == 0 {
+		return false
+	}
+	last := r.Redirects[len(r.Redirects)-1]
+	for _, redirect := range r.Redirects {
+		if redirect.Url == last.Location {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package site
+
+import "testing"
+
+func TestResponse_Loop(t *testing.T) {
+	tests := []struct {
+		name      string
+		redirects []*Redirect
+		want      bool
+	}{
+		{"noRedirects", nil, false},
+		{"redirect", []*Redirect{{Url: "https://monzo.com/old", Status: 301, Location: "https://monzo.com/new"}}, false},
+		{"selfRedirect", []*Redirect{{Url: "https://monzo.com/old", Status: 301, Location: "https://monzo.com/old"}}, true},
+		{"loop", []*Redirect{
+			{Url: "https://monzo.com/a", Status: 302, Location: "https://monzo.com/b"},
+			{Url: "https://monzo.com/b", Status: 302, Location: "https://monzo.com/a"},
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Response{Redirects: tt.redirects}
+			if got := r.Loop(); got != tt.want {
+				t.Errorf("Response.Loop() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
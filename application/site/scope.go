@@ -0,0 +1,87 @@
+package site
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// ScopeMode represent hosts crawl scope mode
+type ScopeMode int
+
+// available ScopeMode constants
+const (
+	ScopeHost   ScopeMode = iota // only host of entry page
+	ScopeWww                     // host of entry page with and without "www." prefix
+	ScopeDomain                  // all subdomains of entry page registrable domain
+	ScopeHosts                   // host of entry page and explicit list of hosts
+	unsupportedScopeMode
+)
+
+// scopeModes is slice of scope mode string representations
+var scopeModes = [...]string{
+	ScopeHost:   "host",
+	ScopeWww:    "www",
+	ScopeDomain: "domain",
+	ScopeHosts:  "hosts",
+}
+
+// String return scope mode enum as a string
+func (m ScopeMode) String() string {
+	return scopeModes[m]
+}
+
+// ParseScopeMode return new ScopeMode enum from given string
+func ParseScopeMode(s string) (ScopeMode, error) {
+	for i, r := range scopeModes {
+		if s == r {
+			return ScopeMode(i), nil
+		}
+	}
+	return unsupportedScopeMode, fmt.Errorf("invalid scope mode value %q", s)
+}
+
+// Scope represent hosts which belong to crawled site
+type Scope struct {
+	Mode  ScopeMode // hosts scope mode
+	Hosts []string  // site hosts of hosts mode
+}
+
+// Contains check if host of given url belongs to
+// the same site as given site page url
+func (s *Scope) Contains(page, u *Url) bool {
+	host, pageHost := strings.ToLower(u.Host), strings.ToLower(page.Host)
+	if host == "" {
+		return false
+	}
+	if host == pageHost {
+		return true
+	}
+	if s == nil {
+		return false
+	}
+
+	switch s.Mode {
+	case ScopeWww:
+		return strings.TrimPrefix(host, "www.") == strings.TrimPrefix(pageHost, "www.")
+	case ScopeDomain:
+		// subdomains of other ports are different sites
+		if u.Port() != page.Port() {
+			return false
+		}
+		domain, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(page.Hostname()))
+		if err != nil {
+			return false
+		}
+		hostname := strings.ToLower(u.Hostname())
+		return hostname == domain || strings.HasSuffix(hostname, "."+domain)
+	case ScopeHosts:
+		for _, h := range s.Hosts {
+			if host == strings.ToLower(h) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package site
+
+import "testing"
+
+func TestParseScopeMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    ScopeMode
+		wantErr bool
+	}{
+		{"host", "host", ScopeHost, false},
+		{"www", "www", ScopeWww, false},
+		{"domain", "domain", ScopeDomain, false},
+		{"hosts", "hosts", ScopeHosts, false},
+		{"unsupported", "subdomains", unsupportedScopeMode, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseScopeMode(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseScopeMode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseScopeMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScope_Contains(t *testing.T) {
+	www := &Scope{Mode: ScopeWww}
+	domain := &Scope{Mode: ScopeDomain}
+	hosts := &Scope{Mode: ScopeHosts, Hosts: []string{"monzo.com", "blog.monzo.com", "monzo.co.uk"}}
+
+	tests := []struct {
+		name  string
+		scope *Scope
+		page  string
+		url   string
+		want  bool
+	}{
+		{"sameHost", nil, "https://monzo.com", "https://monzo.com/blog", true},
+		{"hostCase", nil, "https://monzo.com", "https://MONZO.com/blog", true},
+		{"otherHost", nil, "https://monzo.com", "https://www.monzo.com/blog", false},
+		{"hostInQuery", nil, "https://monzo.com", "https://evil.com/?x=monzo.com", false},
+		{"hostSuffix", nil, "https://monzo.com", "https://evilmonzo.com", false},
+		{"noHost", nil, "https://monzo.com", "mailto:help@monzo.com", false},
+		{"www", www, "https://monzo.com", "https://www.monzo.com/blog", true},
+		{"wwwReverse", www, "https://www.monzo.com", "https://monzo.com/blog", true},
+		{"wwwSubdomain", www, "https://monzo.com", "https://blog.monzo.com", false},
+		{"domainSubdomain", domain, "https://www.monzo.com", "https://community.monzo.com", true},
+		{"domainNested", domain, "https://monzo.com", "https://a.b.monzo.com", true},
+		{"domainRoot", domain, "https://blog.monzo.com", "https://monzo.com", true},
+		{"domainOther", domain, "https://monzo.com", "https://notmonzo.com", false},
+		{"domainPublicSuffix", domain, "https://monzo.co.uk", "https://other.co.uk", false},
+		{"domainPublicSuffixSubdomain", domain, "https://monzo.co.uk", "https://www.monzo.co.uk", true},
+		{"domainOtherPort", domain, "https://monzo.com", "https://blog.monzo.com:8443", false},
+		{"hostsListed", hosts, "https://monzo.com", "https://monzo.co.uk/about", true},
+		{"hostsFromListed", hosts, "https://blog.monzo.com", "https://monzo.com", true},
+		{"hostsNotListed", hosts, "https://monzo.com", "https://community.monzo.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.Contains(getTestUrl(tt.page), getTestUrl(tt.url)); got != tt.want {
+				t.Errorf("Scope.Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSite_SetScope(t *testing.T) {
+	site := getTestSite()
+	site.SetScope(&Scope{Mode: ScopeHosts, Hosts: []string{"monzo.co.uk"}})
+
+	tests := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{"entryHost", "https://monzo.com/blog", true},
+		{"listedHost", "https://monzo.co.uk", true},
+		{"otherHost", "https://monzo.de", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := site.InScope(getTestUrl(tt.url)); got != tt.want {
+				t.Errorf("Site.InScope() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// child pages of tree are validated against site scope
+	if _, err := site.PageTree.AddSubPage("https://monzo.co.uk/about"); err != nil {
+		t.Errorf("Page.AddSubPage() error = %v, want nil", err)
+	}
+}
@@ -1,77 +1,391 @@
 package site
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"errors"
+	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	errAlreadyParsed = errors.New("page have already parsed")
+	errManyVariants  = errors.New("page path has too many query variants")
+	errNoSiteUrl     = errors.New("site output has no url")
+	errNoSitePages   = errors.New("site output has neither hash map nor page tree")
+)
+
+// Crawl limits which can be reached during crawling
+const (
+	LimitDepth = "max_depth" // maximum distance from entry page
+	LimitPages = "max_pages" // maximum number of fetched pages
+
+	LimitVariants = "max_query_variants" // maximum query variants of one path
 )
 
-var errAlreadyParsed = errors.New("page have already parsed")
+// SchemaVersion is version of site output schema,
+// increased on incompatible output changes
+const SchemaVersion = 1
 
 // Site represent Web-site structure
 type Site struct {
-	XMLName    xml.Name     `json:"-" xml:"site"`
-	Url        *Url         `json:"url" xml:"url"`                       // basic site Url
-	TotalPages int          `json:"total_pages" xml:"total_pages"`       // total counts site page
-	PageTree   *Page        `json:"tree,omitempty" xml:"tree,omitempty"` // site page tree
-	HashMap    PagesHashMap `json:"map,omitempty" xml:"map,omitempty"`   // site hash page map
-	mu         *sync.Mutex  `json:"-" xml:"-"`                           // mutex variable for threadsafe operations with maps
+	XMLName    xml.Name            `json:"-" xml:"site"`
+	Version    int                 `json:"version" xml:"version,attr"`                              // site output schema version
+	Url        *Url                `json:"url" xml:"url"`                                           // basic site Url
+	TotalPages int                 `json:"total_pages" xml:"total_pages"`                           // total counts site page
+	PageTree   *Page               `json:"tree,omitempty" xml:"tree,omitempty"`                     // site page tree
+	HashMap    PagesHashMap        `json:"map,omitempty" xml:"map,omitempty"`                       // site hash page map
+	Skipped    SkippedPages        `json:"skipped,omitempty" xml:"skipped,omitempty"`               // pages skipped without crawling
+	Excluded   SkippedPages        `json:"excluded,omitempty" xml:"excluded,omitempty"`             // pages excluded by crawl rules
+	Limits     Limits              `json:"limits_reached,omitempty" xml:"limits_reached,omitempty"` // crawl limits reached during crawling
+	Broken     BrokenLinks         `json:"broken_links,omitempty" xml:"broken_links,omitempty"`     // failed pages with source pages
+	Sources    map[string][]Source `json:"-" xml:"-"`                                               // source pages of every linked page
+	Incomplete bool                `json:"incomplete,omitempty" xml:"incomplete,omitempty"`         // crawling was interrupted before finish
+	Duration   time.Duration       `json:"-" xml:"-"`                                               // crawling duration
+	mu         *sync.Mutex         `json:"-" xml:"-"`                                               // mutex variable for threadsafe operations with maps
+	normalizer *Normalizer         `json:"-" xml:"-"`                                               // site urls normalizer, nil - default one
+	scope      *Scope              `json:"-" xml:"-"`                                               // site hosts scope, nil - only entry page host
+	keys       map[string]bool     `json:"-" xml:"-"`                                               // keys of hash map, skipped and excluded pages
+	variants   map[string]int      `json:"-" xml:"-"`                                               // query variants count of every path key
 }
 
 // NewSite create new site from given target Url
 func NewSite(entryPage *Url) *Site {
+	entryPage = DefaultNormalizer.Normalize(entryPage)
+	tree := NewPage(entryPage)
 	return &Site{
+		Version:  SchemaVersion,
 		Url:      entryPage,
-		PageTree: NewPage(entryPage),
-		HashMap:  make(map[string][]string),
+		PageTree: tree,
+		HashMap:  PagesHashMap{entryPage.String(): tree},
+		Skipped:  make(map[string]string),
+		Excluded: make(map[string]string),
+		Sources:  make(map[string][]Source),
 		mu:       &sync.Mutex{},
 	}
 }
 
-// TODO better way - move it to Page's methods
+// output represent Site without custom unmarshaling methods
+type output Site
+
+// UnmarshalJSON decode site from Json output
+// of hash map or page tree site type
+func (s *Site) UnmarshalJSON(data []byte) error {
+	var out output
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	return s.restore(out)
+}
+
+// UnmarshalXML decode site from Xml output
+// of hash map or page tree site type
+func (s *Site) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var out output
+	if err := d.DecodeElement(&out, &start); err != nil {
+		return err
+	}
+	return s.restore(out)
+}
+
+// restore set site from decoded output and initialize fields,
+// which are not written to output, outputs written before
+// versioning have zero version
+func (s *Site) restore(out output) error {
+	if out.Version > SchemaVersion {
+		return fmt.Errorf("unsupported site output version %d, latest supported version is %d", out.Version, SchemaVersion)
+	}
+	if out.Url == nil {
+		return errNoSiteUrl
+	}
+	if out.HashMap == nil && out.PageTree == nil {
+		return errNoSitePages
+	}
+
+	*s = Site(out)
+	if s.Skipped == nil {
+		s.Skipped = make(map[string]string)
+	}
+	if s.Excluded == nil {
+		s.Excluded = make(map[string]string)
+	}
+	s.Sources = make(map[string][]Source)
+	s.mu = &sync.Mutex{}
+	if s.PageTree != nil {
+		restoreLoggers(s.PageTree)
+	}
+	return nil
+}
+
+// restoreLoggers set loggers of given decoded tree page and its child pages
+func restoreLoggers(page *Page) {
+	page.Logger = logrus.WithField("page", page.Url.String())
+	for _, link := range page.Links {
+		restoreLoggers(link)
+	}
+}
+
+// SetNormalizer set normalizer of site urls, entry page
+// and hash map pages are normalized with given normalizer
+func (s *Site) SetNormalizer(n *Normalizer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.normalizer = n
+	s.keys = nil
+	s.Url = n.Normalize(s.Url)
+	if s.PageTree != nil {
+		s.PageTree.setNormalizer(n)
+	}
+	if s.HashMap != nil {
+		hash := make(PagesHashMap, len(s.HashMap))
+		for _, page := range s.HashMap {
+			page.setNormalizer(n)
+			hash[page.Url.String()] = page
+		}
+		s.HashMap = hash
+	}
+}
 
-// AddPageToParent add Child Page to Parent Page slice in HashMap
-func (s *Site) AddPageToParent(child, parent string) {
+// Normalizer threadsafe return canonical urls normalizer of site pages
+func (s *Site) Normalizer() *Normalizer {
 	s.mu.Lock()
-	s.HashMap[parent] = append(s.HashMap[parent], child)
-	s.mu.Unlock()
+	defer s.mu.Unlock()
+	return s.normalizer
+}
+
+// SetScope set hosts scope of the site,
+// entry page host is always in site hosts
+func (s *Site) SetScope(scope *Scope) {
+	if scope != nil && scope.Mode == ScopeHosts {
+		withEntry := *scope
+		withEntry.Hosts = append([]string{s.Url.Host}, scope.Hosts...)
+		scope = &withEntry
+	}
+	s.scope = scope
+	if s.PageTree != nil {
+		s.PageTree.scope = scope
+	}
+}
+
+// InScope check if given url belongs to site hosts scope
+func (s *Site) InScope(u *Url) bool {
+	return s.scope.Contains(s.Url, u)
 }
 
 // AddPageToSite validate and add given page to current site
-func (s *Site) AddPageToSite(page string) error {
-	// check if page already in main hash map
+func (s *Site) AddPageToSite(page *Page) error {
 	s.mu.Lock()
-	ok := inMap(page, s.HashMap)
-	s.mu.Unlock()
-	if ok {
+	defer s.mu.Unlock()
+
+	// check if page already in main hash map, skipped or excluded
+	key := s.normalizer.Key(page.Url)
+	if s.index()[key] {
 		return errAlreadyParsed
 	}
 
+	// limit distinct query variants of one path against crawler traps
+	path := pathKey(key)
+	if max := s.normalizer.query().MaxVariants; max > 0 && path != key {
+		if s.variants[path] >= max {
+			s.limitReached(LimitVariants)
+			return errManyVariants
+		}
+	}
+
 	// add page to main hash map
+	s.HashMap[page.Url.String()] = page
+	s.addKey(key)
+
+	return nil
+}
+
+// index return keys of hash map, skipped and excluded pages,
+// keys are collected on first call
+func (s *Site) index() map[string]bool {
+	if s.keys == nil {
+		s.keys = make(map[string]bool, len(s.HashMap)+len(s.Skipped)+len(s.Excluded))
+		s.variants = make(map[string]int)
+		for url := range s.HashMap {
+			s.addKey(s.normalizer.KeyString(url))
+		}
+		for url := range s.Skipped {
+			s.addKey(s.normalizer.KeyString(url))
+		}
+		for url := range s.Excluded {
+			s.addKey(s.normalizer.KeyString(url))
+		}
+	}
+	return s.keys
+}
+
+// addKey add given page key to keys index
+// and count query variants of its path
+func (s *Site) addKey(key string) {
+	if s.keys[key] {
+		return
+	}
+	s.keys[key] = true
+	if path := pathKey(key); path != key {
+		s.variants[path]++
+	}
+}
+
+// removeKey remove given page key from keys index
+// and uncount query variant of its path
+func (s *Site) removeKey(key string) {
+	if !s.keys[key] {
+		return
+	}
+	delete(s.keys, key)
+	if path := pathKey(key); path != key {
+		s.variants[path]--
+	}
+}
+
+// pathKey return given page key without query string
+func pathKey(key string) string {
+	return strings.SplitN(key, "?", 2)[0]
+}
+
+// Limits represent reached crawl limits structure type
+type Limits []string
+
+// MarshalXML correct formatted XML marshaling
+// for Limits structure type
+func (l Limits) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Limits []string `xml:"limit"`
+	}{
+		Limits: l}, start)
+}
+
+// UnmarshalXML correct formatted XML unmarshaling
+// for Limits structure type
+func (l *Limits) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var limits struct {
+		Limits []string `xml:"limit"`
+	}
+	if err := d.DecodeElement(&limits, &start); err != nil {
+		return err
+	}
+	*l = limits.Limits
+	return nil
+}
+
+// LimitReached record given crawl limit as reached
+func (s *Site) LimitReached(limit string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limitReached(limit)
+}
+
+// limitReached record given crawl limit as reached
+func (s *Site) limitReached(limit string) {
+	for _, l := range s.Limits {
+		if l == limit {
+			return
+		}
+	}
+	s.Limits = append(s.Limits, limit)
+}
+
+// IncTotalPages threadsafe increase total site pages count
+func (s *Site) IncTotalPages() {
 	s.mu.Lock()
-	s.HashMap[page] = []string{}
+	s.TotalPages++
 	s.mu.Unlock()
+}
 
-	return nil
+// GetTotalPages threadsafe return total site pages count
+func (s *Site) GetTotalPages() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.TotalPages
 }
 
 // DeletePageFromSite delete given page from Site
 func (s *Site) DeletePageFromSite(page string) {
 	s.mu.Lock()
 	delete(s.HashMap, page)
+	if s.keys != nil {
+		s.removeKey(s.normalizer.KeyString(page))
+	}
+	s.mu.Unlock()
+}
+
+// SkipPage remove given page from Site hash map
+// and add it to skipped pages with given reason
+func (s *Site) SkipPage(page, reason string) {
+	s.mu.Lock()
+	delete(s.HashMap, page)
+	s.Skipped[page] = reason
+	if s.keys != nil {
+		s.addKey(s.normalizer.KeyString(page))
+	}
+	s.mu.Unlock()
+}
+
+// ExcludePage remove given page from Site hash map
+// and add it to excluded pages with matched crawl rule
+func (s *Site) ExcludePage(page, rule string) {
+	s.mu.Lock()
+	delete(s.HashMap, page)
+	s.Excluded[page] = rule
+	if s.keys != nil {
+		s.addKey(s.normalizer.KeyString(page))
+	}
 	s.mu.Unlock()
 }
 
-// TODO need refactoring
+// LinkState threadsafe return state of the link to given page url, which
+// is not added to site hash map: excluded and skipped pages links
+// are excluded and skipped too
+func (s *Site) LinkState(page string) PageState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Excluded[page]; ok {
+		return Excluded
+	}
+	if _, ok := s.Skipped[page]; ok {
+		return Skipped
+	}
+	return Linked
+}
+
+// Pages return site pages sorted by url. Pages are taken from hash map,
+// or from page tree if hash map is not available.
+func (s *Site) Pages() []*Page {
+	var pages []*Page
+	switch {
+	case s.HashMap != nil:
+		for _, page := range s.HashMap {
+			pages = append(pages, page)
+		}
+	case s.PageTree != nil:
+		pages = treePages(pages, s.PageTree)
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Url.String() < pages[j].Url.String()
+	})
+	return pages
+}
 
-// inMap check if map contain given link
-func inMap(s string, m map[string][]string) bool {
-	_, ok := m[s]
-	_, okSlash := m[s+"/"]
-	_, okOneMore := m[strings.TrimSuffix(s, "/")]
-	if okSlash || ok || okOneMore {
-		return true
+// treePages append given tree page and its child pages to pages slice,
+// linked pages are only links to pages from other tree nodes, excluded
+// and skipped pages are not site pages, as they are not in hash map
+func treePages(pages []*Page, page *Page) []*Page {
+	switch page.State {
+	case Linked, Excluded, Skipped:
+		return pages
+	}
+	pages = append(pages, page)
+	for _, link := range page.Links {
+		pages = treePages(pages, link)
 	}
-	return false
+	return pages
 }
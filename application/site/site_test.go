@@ -17,9 +17,13 @@ func TestNewSite(t *testing.T) {
 		want *Site
 	}{
 		{"validSite", args{url}, &Site{
+			Version:  SchemaVersion,
 			Url:      url,
 			PageTree: NewPage(url),
-			HashMap:  make(map[string][]string),
+			HashMap:  PagesHashMap{url.String(): NewPage(url)},
+			Skipped:  make(map[string]string),
+			Excluded: make(map[string]string),
+			Sources:  make(map[string][]Source),
 			mu:       &sync.Mutex{},
 		}},
 	}
@@ -36,15 +40,20 @@ func TestSite_AddPageToSite(t *testing.T) {
 	site := getTestSite()
 
 	type args struct {
-		page string
+		page *Page
 	}
 	tests := []struct {
 		name    string
 		args    args
 		wantErr bool
 	}{
-		{"success", args{"https://monzo.com/news"}, false},
-		{"unsuccess", args{"https://monzo.com/news"}, true},
+		{"success", args{getTestPageFromString("https://monzo.com/news")}, false},
+		{"unsuccess", args{getTestPageFromString("https://monzo.com/news")}, true},
+		{"trailingSlash", args{getTestPageFromString("https://monzo.com/news/")}, true},
+		{"indexFile", args{getTestPageFromString("https://monzo.com/news/index.html")}, true},
+		{"hostCase", args{getTestPageFromString("https://MONZO.com:443/news")}, true},
+		{"inTestMap", args{getTestPageFromString("https://monzo.com/blog/haha/")}, true},
+		{"other", args{getTestPageFromString("https://monzo.com/news/other")}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -55,36 +64,128 @@ func TestSite_AddPageToSite(t *testing.T) {
 	}
 }
 
-func Test_inMap(t *testing.T) {
-	site := getTestSite()
-
-	type args struct {
-		s string
-		m map[string][]string
-	}
+func TestSite_SetNormalizer(t *testing.T) {
 	tests := []struct {
-		name string
-		args args
-		want bool
+		name       string
+		normalizer *Normalizer
+		entry      string
+		want       string
+		page       string
+		wantErr    bool
 	}{
-		{"true", args{"https://monzo.com/blog/haha", site.HashMap}, true},
-		{"false", args{"https://monzo.com/blog/bebe", site.HashMap}, false},
+		{"stripQuery", &Normalizer{Query: QueryPolicy{Mode: QueryStrip}}, "https://monzo.com/blog?utm_source=mail&page=2", "https://monzo.com/blog?page=2", "https://monzo.com/blog?page=2", true},
+		{"allowQuery", &Normalizer{Query: QueryPolicy{Mode: QueryAllow, Params: []string{"page"}}}, "https://monzo.com/blog?sort=new&page=2", "https://monzo.com/blog?page=2", "https://monzo.com/blog?page=2&sort=old", true},
+		{"indexFile", &Normalizer{IndexFiles: []string{"index.html"}}, "https://monzo.com/blog/index.html", "https://monzo.com/blog/index.html", "https://monzo.com/blog/", true},
+		{"strictSlash", &Normalizer{}, "https://monzo.com/blog/", "https://monzo.com/blog/", "https://monzo.com/blog", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := inMap(tt.args.s, tt.args.m); got != tt.want {
-				t.Errorf("inMap() = %v, want %v", got, tt.want)
+			url, _ := ParseRequestURI(tt.entry)
+			site := NewSite(url)
+			site.SetNormalizer(tt.normalizer)
+
+			if got := site.Url.String(); got != tt.want {
+				t.Errorf("Site.SetNormalizer() url = %v, want %v", got, tt.want)
+			}
+			if got := site.PageTree.Url.String(); got != tt.want {
+				t.Errorf("Site.SetNormalizer() entry page url = %v, want %v", got, tt.want)
+			}
+			if site.HashMap[tt.want] != site.PageTree || len(site.HashMap) != 1 {
+				t.Errorf("Site.SetNormalizer() hash map = %v, want entry page by %v", site.HashMap, tt.want)
+			}
+
+			page := getTestPageFromString(tt.page)
+			page.Url = tt.normalizer.Normalize(page.Url)
+			if err := site.AddPageToSite(page); (err != nil) != tt.wantErr {
+				t.Errorf("Site.AddPageToSite() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
+func TestSite_SkipPage(t *testing.T) {
+	site := getTestSite()
+	site.SkipPage("https://monzo.com/blog/haha", "robots.txt Disallow: /blog/haha")
+
+	if _, ok := site.HashMap["https://monzo.com/blog/haha"]; ok {
+		t.Errorf("Site.SkipPage() skipped page left in hash map")
+	}
+	if reason := site.Skipped["https://monzo.com/blog/haha"]; reason != "robots.txt Disallow: /blog/haha" {
+		t.Errorf("Site.SkipPage() reason = %v, want %v", reason, "robots.txt Disallow: /blog/haha")
+	}
+	if err := site.AddPageToSite(getTestPageFromString("https://monzo.com/blog/haha")); err == nil {
+		t.Errorf("Site.AddPageToSite() skipped page added again")
+	}
+}
+
+func TestSite_ExcludePage(t *testing.T) {
+	site := getTestSite()
+	site.ExcludePage("https://monzo.com/blog/haha", "exclude /blog/**")
+
+	if _, ok := site.HashMap["https://monzo.com/blog/haha"]; ok {
+		t.Errorf("Site.ExcludePage() excluded page left in hash map")
+	}
+	if rule := site.Excluded["https://monzo.com/blog/haha"]; rule != "exclude /blog/**" {
+		t.Errorf("Site.ExcludePage() rule = %v, want %v", rule, "exclude /blog/**")
+	}
+	if err := site.AddPageToSite(getTestPageFromString("https://monzo.com/blog/haha/")); err == nil {
+		t.Errorf("Site.AddPageToSite() excluded page added again")
+	}
+}
+
+func TestSite_LimitReached(t *testing.T) {
+	site := getTestSite()
+	site.LimitReached(LimitDepth)
+	site.LimitReached(LimitPages)
+	site.LimitReached(LimitDepth)
+
+	want := Limits{LimitDepth, LimitPages}
+	if !reflect.DeepEqual(site.Limits, want) {
+		t.Errorf("Site.LimitReached() limits = %v, want %v", site.Limits, want)
+	}
+}
+
 func getTestSite() *Site {
 	url, _ := ParseRequestURI("https://monzo.com")
-	subUrl, _ := url.ParseUrl("https://monzo.com/blog")
-	subSubUrl, _ := url.ParseUrl("https://monzo.com/blog/haha")
 	site := NewSite(url)
-	site.HashMap[subUrl.String()] = []string{subSubUrl.String()}
-	site.HashMap[subSubUrl.String()] = []string{}
+	blog, _ := site.PageTree.AddSubPage("/blog")
+	haha, _ := blog.AddSubPage("/blog/haha")
+	site.HashMap[blog.Url.String()] = blog
+	site.HashMap[haha.Url.String()] = haha
 	return site
 }
+
+func getTestPageFromString(rawUrl string) *Page {
+	url, _ := ParseRequestURI(rawUrl)
+	return NewPage(url)
+}
+
+func TestSite_Pages(t *testing.T) {
+	site := getTestSite()
+	for _, page := range site.HashMap {
+		page.State = Crawled
+	}
+	want := []string{"https://monzo.com", "https://monzo.com/blog", "https://monzo.com/blog/haha"}
+
+	tests := []struct {
+		name   string
+		format func(s *Site)
+	}{
+		{"hashMap", func(s *Site) { s.PageTree = nil }},
+		{"pageTree", func(s *Site) { s.HashMap = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := *site
+			tt.format(&s)
+
+			var got []string
+			for _, page := range s.Pages() {
+				got = append(got, page.Url.String())
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Site.Pages() = %v, want %v", got, want)
+			}
+		})
+	}
+}
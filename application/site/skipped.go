@@ -0,0 +1,77 @@
+package site
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"sort"
+)
+
+// SkippedPages represent skipped pages structure type
+// with page url as a key and skip reason as a value
+type SkippedPages map[string]string
+
+// MarshalJSON correct formatted JSON marshaling
+// for Skipped Pages structure type
+func (s SkippedPages) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.mapToSkippedPages())
+}
+
+// MarshalXML correct formatted XML marshaling
+// for Skipped Pages structure type
+func (s SkippedPages) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		Pages []skippedPage `xml:"page"`
+	}{
+		Pages: s.mapToSkippedPages()}, start)
+}
+
+// UnmarshalJSON correct formatted JSON unmarshaling
+// for Skipped Pages structure type
+func (s *SkippedPages) UnmarshalJSON(data []byte) error {
+	var pages []skippedPage
+	if err := json.Unmarshal(data, &pages); err != nil {
+		return err
+	}
+	*s = skippedPagesToMap(pages)
+	return nil
+}
+
+// UnmarshalXML correct formatted XML unmarshaling
+// for Skipped Pages structure type
+func (s *SkippedPages) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var skipped struct {
+		Pages []skippedPage `xml:"page"`
+	}
+	if err := d.DecodeElement(&skipped, &start); err != nil {
+		return err
+	}
+	*s = skippedPagesToMap(skipped.Pages)
+	return nil
+}
+
+// skippedPage represent SkippedPages formatter for XML and JSON marshaling
+type skippedPage struct {
+	Url    string `json:"url" xml:"url"`
+	Reason string `json:"reason" xml:"reason"`
+}
+
+// mapToSkippedPages create sorted slice of skippedPage from SkippedPages
+func (s SkippedPages) mapToSkippedPages() []skippedPage {
+	pages := make([]skippedPage, 0, len(s))
+	for url, reason := range s {
+		pages = append(pages, skippedPage{Url: url, Reason: reason})
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Url < pages[j].Url
+	})
+	return pages
+}
+
+// skippedPagesToMap create SkippedPages from slice of skippedPage
+func skippedPagesToMap(pages []skippedPage) SkippedPages {
+	skipped := make(SkippedPages, len(pages))
+	for _, page := range pages {
+		skipped[page.Url] = page.Reason
+	}
+	return skipped
+}
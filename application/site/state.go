@@ -0,0 +1,68 @@
+package site
+
+import "fmt"
+
+// PageState is Enum that represent
+// crawling state of the site page
+type PageState int
+
+// available Page State constants
+const (
+	Linked     PageState = iota // page is a link to page crawled in other tree node
+	Queued                      // page is waiting for crawling
+	Crawled                     // page is fetched and parsed
+	Failed                      // page fetching is failed
+	NotFetched                  // page is found, but not fetched because of crawl limits
+	Redirected                  // page redirects to external host, redirect is not followed
+	Excluded                    // page is excluded from crawling by crawl rules
+	Skipped                     // page is disallowed by robots.txt
+	unsupportedState
+)
+
+// states is slice of page states string representations
+var states = [...]string{
+	Linked:     "linked",
+	Queued:     "queued",
+	Crawled:    "crawled",
+	Failed:     "failed",
+	NotFetched: "not_fetched",
+	Redirected: "redirected",
+	Excluded:   "excluded",
+	Skipped:    "skipped",
+}
+
+// String return page state enum as a string
+func (s PageState) String() string {
+	return states[s]
+}
+
+// Fetched check if page crawling is finished with fetch attempt
+func (s PageState) Fetched() bool {
+	switch s {
+	case Crawled, Failed, Redirected:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParsePageState return new PageState enum from given string
+func ParsePageState(s string) (PageState, error) {
+	for i, r := range states {
+		if s == r {
+			return PageState(i), nil
+		}
+	}
+	return unsupportedState, fmt.Errorf("invalid Page State value %q", s)
+}
+
+// MarshalText provide page state text marshaling for Json and Xml
+func (s PageState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText provide page state text unmarshaling for Json and Xml
+func (s *PageState) UnmarshalText(text []byte) (err error) {
+	*s, err = ParsePageState(string(text))
+	return
+}
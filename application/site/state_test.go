@@ -0,0 +1,57 @@
+package site
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParsePageState(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    PageState
+		wantErr bool
+	}{
+		{"crawled", "crawled", Crawled, false},
+		{"notFetched", "not_fetched", NotFetched, false},
+		{"redirected", "redirected", Redirected, false},
+		{"excluded", "excluded", Excluded, false},
+		{"skipped", "skipped", Skipped, false},
+		{"invalid", "lost", unsupportedState, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePageState(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePageState() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParsePageState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageState_MarshalText(t *testing.T) {
+	tests := []struct {
+		name  string
+		state PageState
+		want  string
+	}{
+		{"queued", Queued, `"queued"`},
+		{"notFetched", NotFetched, `"not_fetched"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.state)
+			if err != nil {
+				t.Errorf("PageState.MarshalText() error = %v", err)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("PageState.MarshalText() = %s, want %v", got, tt.want)
+			}
+		})
+	}
+}
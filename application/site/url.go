@@ -36,6 +36,15 @@ func ParseRequestURI(rawUrl string) (*Url, error) {
 	return &Url{uri}, err
 }
 
+// parseUrl parses rawUrl of previously written output into a URL structure
+func parseUrl(rawUrl string) (*Url, error) {
+	uri, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+	return &Url{uri}, nil
+}
+
 // MarshalJSON provide corrects Url Json marshaling
 func (u Url) MarshalJSON() ([]byte, error) {
 	return json.Marshal(u.String())
@@ -45,3 +54,31 @@ func (u Url) MarshalJSON() ([]byte, error) {
 func (u Url) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	return e.EncodeElement(u.String(), start)
 }
+
+// UnmarshalJSON provide Url Json unmarshaling
+func (u *Url) UnmarshalJSON(data []byte) error {
+	var rawUrl string
+	if err := json.Unmarshal(data, &rawUrl); err != nil {
+		return err
+	}
+	parsed, err := parseUrl(rawUrl)
+	if err != nil {
+		return err
+	}
+	*u = *parsed
+	return nil
+}
+
+// UnmarshalXML provide Url Xml unmarshaling
+func (u *Url) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var rawUrl string
+	if err := d.DecodeElement(&rawUrl, &start); err != nil {
+		return err
+	}
+	parsed, err := parseUrl(rawUrl)
+	if err != nil {
+		return err
+	}
+	*u = *parsed
+	return nil
+}
@@ -0,0 +1,177 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+var errUnsupportedData = errors.New("csv writer supports only site and broken links report")
+
+// Output files columns
+var (
+	edgesHeader  = []string{"source", "target", "anchor_text", "target_status"}
+	pagesHeader  = []string{"url", "depth", "status", "state", "in_links", "out_links", "content_type"}
+	brokenHeader = []string{"url", "status", "error", "source", "anchor_text"}
+)
+
+// WriterCsv represent CSV and TSV implementation of the IWriter interface:
+// site is written as edge list with one row per link
+// and pages list with one row per page in separate file
+type WriterCsv struct {
+	Comma rune // fields delimiter, ',' for CSV or '\t' for TSV
+}
+
+// Write writes links edge list of providing site or broken links
+// of providing report to given writer, e.g. stdout, pages list
+// is written only by WriteTo
+func (w WriterCsv) Write(out io.Writer, data interface{}) error {
+	switch data := data.(type) {
+	case *site.Site:
+		return w.write(out, edgesHeader, edgesRows(data))
+	case *site.BrokenLinksReport:
+		return w.write(out, brokenHeader, brokenRows(data))
+	default:
+		return errUnsupportedData
+	}
+}
+
+// WriteTo writes links edge list of providing site to given file
+// and pages list to file with "-pages" suffix, e.g. "monzo.com-pages.csv",
+// broken links report is written to given file only
+func (w WriterCsv) WriteTo(data interface{}, fileName string) error {
+	if err := w.writeFile(fileName, data); err != nil {
+		return err
+	}
+
+	s, ok := data.(*site.Site)
+	if !ok {
+		return nil
+	}
+
+	file, err := os.Create(PagesFilename(fileName))
+	if err != nil {
+		return err
+	}
+	if err := w.write(file, pagesHeader, pagesRows(s)); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// writeFile writes providing data to given file
+func (w WriterCsv) writeFile(fileName string, data interface{}) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	if err := w.Write(file, data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// write writes header and rows to given writer with proper escaping
+func (w WriterCsv) write(out io.Writer, header []string, rows [][]string) error {
+	writer := csv.NewWriter(out)
+	if w.Comma != 0 {
+		writer.Comma = w.Comma
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return nil
+}
+
+// edgesRows return rows of site links edge list
+func edgesRows(s *site.Site) [][]string {
+	anchors := s.Anchors()
+
+	var rows [][]string
+	for _, link := range site.Links(s.Pages()) {
+		rows = append(rows, []string{
+			link.Source.Url.String(),
+			link.Url,
+			anchors.Text(link.Source.Url.String(), link.Url),
+			status(link.Target),
+		})
+	}
+	return rows
+}
+
+// pagesRows return rows of site pages list with inbound and outbound links counts
+func pagesRows(s *site.Site) [][]string {
+	pages := s.Pages()
+
+	inLinks := make(map[*site.Page]int)
+	for _, link := range site.Links(pages) {
+		if link.Target != nil {
+			inLinks[link.Target]++
+		}
+	}
+
+	rows := make([][]string, 0, len(pages))
+	for _, page := range pages {
+		var contentType string
+		if page.Response != nil {
+			contentType = page.Response.ContentType
+		}
+		rows = append(rows, []string{
+			page.Url.String(),
+			strconv.Itoa(page.Depth),
+			status(page),
+			page.State.String(),
+			strconv.Itoa(inLinks[page]),
+			strconv.Itoa(len(page.Links)),
+			contentType,
+		})
+	}
+	return rows
+}
+
+// brokenRows return rows of broken links report,
+// one row for every source page of broken link
+func brokenRows(report *site.BrokenLinksReport) [][]string {
+	var rows [][]string
+	for _, link := range report.Links {
+		linkStatus := ""
+		if link.Status != 0 {
+			linkStatus = strconv.Itoa(link.Status)
+		}
+		if len(link.Sources) == 0 {
+			rows = append(rows, []string{link.Url, linkStatus, link.Error, "", ""})
+		}
+		for _, source := range link.Sources {
+			rows = append(rows, []string{link.Url, linkStatus, link.Error, source.Url, source.Text})
+		}
+	}
+	return rows
+}
+
+// status return response status code of given page,
+// empty if page is not in site or is not fetched
+func status(page *site.Page) string {
+	if page == nil || page.Response == nil || page.Response.Status == 0 {
+		return ""
+	}
+	return strconv.Itoa(page.Response.Status)
+}
+
+// PagesFilename return name of pages list file
+// for given edge list file name
+func PagesFilename(fileName string) string {
+	ext := filepath.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + "-pages" + ext
+}
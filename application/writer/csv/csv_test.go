@@ -0,0 +1,122 @@
+package csv
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+func TestWriterCsv_Write(t *testing.T) {
+	s := getTestSite()
+	s.FindBrokenLinks()
+
+	tests := []struct {
+		name    string
+		comma   rune
+		data    interface{}
+		want    string
+		wantErr bool
+	}{
+		{"csv", ',', s, `source,target,anchor_text,target_status
+https://monzo.com,https://monzo.com/about,"About ""us"", team",200
+https://monzo.com,https://monzo.com/missing,Missing,404
+https://monzo.com/about,https://monzo.com/,Home,200
+https://monzo.com/about,https://monzo.com/deep,,
+`, false},
+		{"tsv", '\t', s, "source\ttarget\tanchor_text\ttarget_status\n" +
+			"https://monzo.com\thttps://monzo.com/about\t\"About \"\"us\"\", team\"\t200\n" +
+			"https://monzo.com\thttps://monzo.com/missing\tMissing\t404\n" +
+			"https://monzo.com/about\thttps://monzo.com/\tHome\t200\n" +
+			"https://monzo.com/about\thttps://monzo.com/deep\t\t\n", false},
+		{"brokenLinks", ',', site.NewBrokenLinksReport(s), `url,status,error,source,anchor_text
+https://monzo.com/missing,404,server responded 404 Not Found,https://monzo.com,Missing
+`, false},
+		{"unsupported", ',', "sitemap", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (WriterCsv{Comma: tt.comma}).Write(&buf, tt.data); (err != nil) != tt.wantErr {
+				t.Errorf("WriterCsv.Write() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriterCsv.Write() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterCsv_WriteTo(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "monzo.com.csv")
+	if err := (WriterCsv{Comma: ','}).WriteTo(getTestSite(), fileName); err != nil {
+		t.Fatalf("WriterCsv.WriteTo() error = %v", err)
+	}
+
+	got, err := ioutil.ReadFile(PagesFilename(fileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `url,depth,status,state,in_links,out_links,content_type
+https://monzo.com,0,200,crawled,1,2,text/html
+https://monzo.com/about,1,200,crawled,1,2,text/html
+https://monzo.com/deep,2,,not_fetched,1,0,
+https://monzo.com/missing,1,404,failed,1,0,text/html
+`
+	if string(got) != want {
+		t.Errorf("WriterCsv.WriteTo() pages = %s, want %s", got, want)
+	}
+	if _, err := ioutil.ReadFile(fileName); err != nil {
+		t.Errorf("WriterCsv.WriteTo() edge list is not written: %v", err)
+	}
+}
+
+func TestPagesFilename(t *testing.T) {
+	tests := []struct {
+		fileName string
+		want     string
+	}{
+		{"monzo.com.csv", "monzo.com-pages.csv"},
+		{"out/links.tsv", "out/links-pages.tsv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			if got := PagesFilename(tt.fileName); got != tt.want {
+				t.Errorf("PagesFilename() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// getTestSite return crawled site with
+// failed and not fetched pages
+func getTestSite() *site.Site {
+	url, _ := site.ParseRequestURI("https://monzo.com")
+	s := site.NewSite(url)
+	html := &site.Response{Status: 200, ContentType: "text/html"}
+	s.PageTree.State = site.Crawled
+	s.PageTree.Response = html
+
+	about, _ := s.PageTree.AddSubPage("/about")
+	about.State = site.Crawled
+	about.Response = html
+	s.AddSource(about.Url.String(), site.Source{Url: "https://monzo.com", Text: `About "us", team`})
+	missing, _ := s.PageTree.AddSubPage("/missing")
+	missing.State = site.Failed
+	missing.Error = "server responded 404 Not Found"
+	missing.Response = &site.Response{Status: 404, ContentType: "text/html"}
+	s.AddSource(missing.Url.String(), site.Source{Url: "https://monzo.com", Text: "Missing"})
+
+	home, _ := about.AddSubPage("/")
+	s.AddSource(home.Url.String(), site.Source{Url: "https://monzo.com/about", Text: "Home"})
+	deep, _ := about.AddSubPage("/deep")
+	deep.State = site.NotFetched
+
+	for _, page := range []*site.Page{about, missing, deep} {
+		s.HashMap[page.Url.String()] = page
+	}
+	return s
+}
@@ -12,13 +12,46 @@ type Format int
 const (
 	JSON Format = iota
 	XML
+	JUNIT
+	SITEMAP
+	NDJSON
+	CSV
+	TSV
+	DOT
+	GRAPHML
+	GEXF
+	HTML
 	unsupported
 )
 
 // writers is slice of writer string representations
 var formats = [...]string{
-	JSON: "json",
-	XML:  "xml",
+	JSON:    "json",
+	XML:     "xml",
+	JUNIT:   "junit",
+	SITEMAP: "sitemap",
+	NDJSON:  "ndjson",
+	CSV:     "csv",
+	TSV:     "tsv",
+	DOT:     "dot",
+	GRAPHML: "graphml",
+	GEXF:    "gexf",
+	HTML:    "html",
+}
+
+// extensions is slice of writer files extensions
+var extensions = [...]string{
+	JSON:    "json",
+	XML:     "xml",
+	JUNIT:   "xml",
+	SITEMAP: "xml",
+	NDJSON:  "ndjson",
+	CSV:     "csv",
+	TSV:     "tsv",
+	DOT:     "dot",
+	GRAPHML: "graphml",
+	GEXF:    "gexf",
+	HTML:    "html",
 }
 
 // String return writer enum as a string
@@ -26,6 +59,11 @@ func (w Format) String() string {
 	return formats[w]
 }
 
+// Extension return file extension of writer format
+func (w Format) Extension() string {
+	return extensions[w]
+}
+
 // ParseFormats return new Format enum from given string
 func ParseFormats(s string) (Format, error) {
 	for i, r := range formats {
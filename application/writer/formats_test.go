@@ -13,6 +13,15 @@ func TestFormat_String(t *testing.T) {
 	}{
 		{"getJson", JSON, "json"},
 		{"getXml", XML, "xml"},
+		{"getJunit", JUNIT, "junit"},
+		{"getSitemap", SITEMAP, "sitemap"},
+		{"getNdjson", NDJSON, "ndjson"},
+		{"getCsv", CSV, "csv"},
+		{"getTsv", TSV, "tsv"},
+		{"getDot", DOT, "dot"},
+		{"getGraphml", GRAPHML, "graphml"},
+		{"getGexf", GEXF, "gexf"},
+		{"getHtml", HTML, "html"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -36,6 +45,12 @@ func TestParseFormats(t *testing.T) {
 	}{
 		{"getJson", args{"json"}, JSON, false},
 		{"getXml", args{"xml"}, XML, false},
+		{"getJunit", args{"junit"}, JUNIT, false},
+		{"getSitemap", args{"sitemap"}, SITEMAP, false},
+		{"getNdjson", args{"ndjson"}, NDJSON, false},
+		{"getTsv", args{"tsv"}, TSV, false},
+		{"getGexf", args{"gexf"}, GEXF, false},
+		{"getHtml", args{"html"}, HTML, false},
 		{"invalid", args{"invalid"}, unsupported, true},
 	}
 	for _, tt := range tests {
@@ -51,3 +66,27 @@ func TestParseFormats(t *testing.T) {
 		})
 	}
 }
+
+func TestFormat_Extension(t *testing.T) {
+	tests := []struct {
+		name string
+		w    Format
+		want string
+	}{
+		{"json", JSON, "json"},
+		{"xml", XML, "xml"},
+		{"junit", JUNIT, "xml"},
+		{"sitemap", SITEMAP, "xml"},
+		{"ndjson", NDJSON, "ndjson"},
+		{"tsv", TSV, "tsv"},
+		{"graphml", GRAPHML, "graphml"},
+		{"html", HTML, "html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.w.Extension(); got != tt.want {
+				t.Errorf("Format.Extension() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
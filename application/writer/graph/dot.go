@@ -0,0 +1,44 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriterDot represent Graphviz DOT implementation of the IWriter interface
+type WriterDot struct {
+	Cluster int // number of url path segments to collapse pages by, 0 - no clustering
+}
+
+// Write writes links graph of providing site to given writer
+func (w WriterDot) Write(out io.Writer, data interface{}) error {
+	graph, err := NewGraph(data, w.Cluster)
+	if err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(out)
+	fmt.Fprintf(buf, "digraph %s {\n", quote(graph.Name))
+	for _, node := range graph.Nodes {
+		fmt.Fprintf(buf, " %s [label=%s, depth=%d, pages=%d", quote(node.Url), quote(node.Url), node.Depth, node.Pages)
+		if node.Status != 0 {
+			fmt.Fprintf(buf, ", status=%d", node.Status)
+		}
+		if node.State != "" {
+			fmt.Fprintf(buf, ", state=%s", quote(node.State))
+		}
+		fmt.Fprintln(buf, "];")
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(buf, " %s -> %s [weight=%d];\n", quote(edge.Source.Url), quote(edge.Target.Url), edge.Weight)
+	}
+	fmt.Fprintln(buf, "}")
+	return buf.Flush()
+}
+
+// quote return DOT quoted string
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,110 @@
+package graph
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// WriterGexf represent GEXF implementation of the IWriter interface
+type WriterGexf struct {
+	Cluster int // number of url path segments to collapse pages by, 0 - no clustering
+}
+
+// gexf represent GEXF document root element
+type gexf struct {
+	XMLName xml.Name  `xml:"gexf"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+// gexfGraph represent GEXF graph element
+type gexfGraph struct {
+	Mode       string         `xml:"mode,attr"`
+	EdgeType   string         `xml:"defaultedgetype,attr"`
+	Attributes gexfAttributes `xml:"attributes"`
+	Nodes      []gexfNode     `xml:"nodes>node"`
+	Edges      []gexfEdge     `xml:"edges>edge"`
+}
+
+// gexfAttributes represent declarations of nodes attributes
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+// gexfAttribute represent declaration of nodes attribute
+type gexfAttribute struct {
+	Id    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+// gexfNode represent GEXF node element
+type gexfNode struct {
+	Id     string          `xml:"id,attr"`
+	Label  string          `xml:"label,attr"`
+	Values []gexfAttrValue `xml:"attvalues>attvalue"`
+}
+
+// gexfAttrValue represent node attribute value
+type gexfAttrValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// gexfEdge represent GEXF edge element
+type gexfEdge struct {
+	Id     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+	Weight int    `xml:"weight,attr"`
+}
+
+// nodeAttributes is declarations of written nodes attributes
+var nodeAttributes = []gexfAttribute{
+	{"depth", "depth", "integer"},
+	{"status", "status", "integer"},
+	{"state", "state", "string"},
+	{"pages", "pages", "integer"},
+}
+
+// Write writes links graph of providing site to given writer
+func (w WriterGexf) Write(out io.Writer, data interface{}) error {
+	graph, err := NewGraph(data, w.Cluster)
+	if err != nil {
+		return err
+	}
+
+	doc := gexf{
+		Xmlns:   "http://www.gexf.net/1.2draft",
+		Version: "1.2",
+		Graph: gexfGraph{
+			Mode:       "static",
+			EdgeType:   "directed",
+			Attributes: gexfAttributes{Class: "node", Attributes: nodeAttributes},
+		},
+	}
+	for _, node := range graph.Nodes {
+		values := []gexfAttrValue{{"depth", strconv.Itoa(node.Depth)}}
+		if node.Status != 0 {
+			values = append(values, gexfAttrValue{"status", strconv.Itoa(node.Status)})
+		}
+		if node.State != "" {
+			values = append(values, gexfAttrValue{"state", node.State})
+		}
+		values = append(values, gexfAttrValue{"pages", strconv.Itoa(node.Pages)})
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfNode{Id: node.Id, Label: node.Url, Values: values})
+	}
+	for _, edge := range graph.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfEdge{
+			Id:     edge.Id,
+			Source: edge.Source.Id,
+			Target: edge.Target.Id,
+			Weight: edge.Weight,
+		})
+	}
+
+	return writeXml(out, doc)
+}
@@ -0,0 +1,130 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+var errUnsupportedData = errors.New("graph writers support only site")
+
+// Graph represent directed links graph of the site
+type Graph struct {
+	Name  string  // graph name, site host
+	Nodes []*Node // pages or clusters of pages, sorted by url
+	Edges []*Edge // links between nodes, sorted by source and target
+}
+
+// Node represent site page or cluster of pages with common path prefix
+type Node struct {
+	Id     string // node identifier
+	Url    string // page Url or cluster path prefix Url
+	Depth  int    // minimal distance of node pages from site entry page
+	Status int    // response status of the page, 0 - unknown
+	State  string // crawling state of the page, empty - unknown
+	Pages  int    // number of pages in node
+}
+
+// Edge represent links between two nodes
+type Edge struct {
+	Id     string // edge identifier
+	Source *Node  // node of pages containing links
+	Target *Node  // node of linked pages
+	Weight int    // number of links
+}
+
+// NewGraph create links graph of given site,
+// with positive cluster pages are collapsed to nodes
+// by first cluster segments of their url path
+func NewGraph(data interface{}, cluster int) (*Graph, error) {
+	s, ok := data.(*site.Site)
+	if !ok {
+		return nil, errUnsupportedData
+	}
+
+	// collect pages to nodes by their keys
+	nodes := make(map[string]*Node)
+	pages := s.Pages()
+	pageNodes := make(map[*site.Page]*Node, len(pages))
+	for _, page := range pages {
+		key := nodeKey(page.Url, cluster)
+		node, ok := nodes[key]
+		if !ok {
+			node = &Node{Url: key, Depth: page.Depth}
+			nodes[key] = node
+		}
+		node.Pages++
+		if page.Depth < node.Depth {
+			node.Depth = page.Depth
+		}
+
+		// cluster represent status of its prefix page
+		if strings.TrimSuffix(page.Url.String(), "/") == strings.TrimSuffix(key, "/") {
+			node.State = page.State.String()
+			if page.Response != nil {
+				node.Status = page.Response.Status
+			}
+		}
+		pageNodes[page] = node
+	}
+
+	// aggregate links between nodes, links to pages out of site
+	// and links inside one cluster are skipped
+	type pair struct{ source, target *Node }
+	weights := make(map[pair]int)
+	for _, link := range site.Links(pages) {
+		if link.Target == nil {
+			continue
+		}
+		source, target := pageNodes[link.Source], pageNodes[link.Target]
+		if cluster > 0 && source == target {
+			continue
+		}
+		weights[pair{source, target}]++
+	}
+
+	graph := &Graph{Name: s.Url.Host}
+	for _, node := range nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].Url < graph.Nodes[j].Url })
+	for i, node := range graph.Nodes {
+		node.Id = fmt.Sprintf("n%d", i)
+	}
+
+	for p, weight := range weights {
+		graph.Edges = append(graph.Edges, &Edge{Source: p.source, Target: p.target, Weight: weight})
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Source.Url != b.Source.Url {
+			return a.Source.Url < b.Source.Url
+		}
+		return a.Target.Url < b.Target.Url
+	})
+	for i, edge := range graph.Edges {
+		edge.Id = fmt.Sprintf("e%d", i)
+	}
+
+	return graph, nil
+}
+
+// nodeKey return node key of page with given url: url itself
+// or url of its first cluster path segments without trailing slash
+func nodeKey(url *site.Url, cluster int) string {
+	if cluster < 1 {
+		return url.String()
+	}
+
+	segments := strings.FieldsFunc(url.Path, func(r rune) bool { return r == '/' })
+	if len(segments) > cluster {
+		segments = segments[:cluster]
+	}
+	if len(segments) == 0 {
+		return fmt.Sprintf("%s://%s", url.Scheme, url.Host)
+	}
+	return fmt.Sprintf("%s://%s/%s", url.Scheme, url.Host, strings.Join(segments, "/"))
+}
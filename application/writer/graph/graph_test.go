@@ -0,0 +1,238 @@
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+func TestNewGraph(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      interface{}
+		cluster   int
+		wantNodes []string
+		wantEdges []string
+		wantErr   bool
+	}{
+		{"pages", getTestSite(), 0, []string{
+			"n0 https://monzo.com depth=0 status=200 state=crawled pages=1",
+			"n1 https://monzo.com/about depth=1 status=0 state=not_fetched pages=1",
+			"n2 https://monzo.com/blog depth=1 status=200 state=crawled pages=1",
+			"n3 https://monzo.com/blog/a depth=2 status=200 state=crawled pages=1",
+			"n4 https://monzo.com/blog/b depth=2 status=404 state=failed pages=1",
+		}, []string{
+			"e0 https://monzo.com -> https://monzo.com/about weight=1",
+			"e1 https://monzo.com -> https://monzo.com/blog weight=1",
+			"e2 https://monzo.com/blog -> https://monzo.com weight=1",
+			"e3 https://monzo.com/blog -> https://monzo.com/blog/a weight=1",
+			"e4 https://monzo.com/blog -> https://monzo.com/blog/b weight=1",
+			"e5 https://monzo.com/blog/a -> https://monzo.com/blog/b weight=1",
+		}, false},
+		{"cluster", getTestSite(), 1, []string{
+			"n0 https://monzo.com depth=0 status=200 state=crawled pages=1",
+			"n1 https://monzo.com/about depth=1 status=0 state=not_fetched pages=1",
+			"n2 https://monzo.com/blog depth=1 status=200 state=crawled pages=3",
+		}, []string{
+			"e0 https://monzo.com -> https://monzo.com/about weight=1",
+			"e1 https://monzo.com -> https://monzo.com/blog weight=1",
+			"e2 https://monzo.com/blog -> https://monzo.com weight=1",
+		}, false},
+		{"unsupported", "sitemap", 0, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph, err := NewGraph(tt.data, tt.cluster)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewGraph() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var nodes, edges []string
+			for _, n := range graph.Nodes {
+				nodes = append(nodes, fmt.Sprintf("%s %s depth=%d status=%d state=%s pages=%d", n.Id, n.Url, n.Depth, n.Status, n.State, n.Pages))
+			}
+			for _, e := range graph.Edges {
+				edges = append(edges, fmt.Sprintf("%s %s -> %s weight=%d", e.Id, e.Source.Url, e.Target.Url, e.Weight))
+			}
+			if !reflect.DeepEqual(nodes, tt.wantNodes) {
+				t.Errorf("NewGraph() nodes = %v, want %v", nodes, tt.wantNodes)
+			}
+			if !reflect.DeepEqual(edges, tt.wantEdges) {
+				t.Errorf("NewGraph() edges = %v, want %v", edges, tt.wantEdges)
+			}
+		})
+	}
+}
+
+func Test_nodeKey(t *testing.T) {
+	tests := []struct {
+		url     string
+		cluster int
+		want    string
+	}{
+		{"https://monzo.com/blog/a/b", 0, "https://monzo.com/blog/a/b"},
+		{"https://monzo.com/blog/a/b", 1, "https://monzo.com/blog"},
+		{"https://monzo.com/blog/a/b", 2, "https://monzo.com/blog/a"},
+		{"https://monzo.com/blog/", 1, "https://monzo.com/blog"},
+		{"https://monzo.com/", 1, "https://monzo.com"},
+		{"https://monzo.com/", 0, "https://monzo.com/"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s-%d", tt.url, tt.cluster), func(t *testing.T) {
+			url, _ := site.ParseRequestURI(tt.url)
+			if got := nodeKey(url, tt.cluster); got != tt.want {
+				t.Errorf("nodeKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriters_Write(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(w io.Writer, data interface{}) error
+		want  string
+	}{
+		{"dot", WriterDot{Cluster: 1}.Write, `digraph "monzo.com" {
+ "https://monzo.com" [label="https://monzo.com", depth=0, pages=1, status=200, state="crawled"];
+ "https://monzo.com/about" [label="https://monzo.com/about", depth=1, pages=1, state="not_fetched"];
+ "https://monzo.com/blog" [label="https://monzo.com/blog", depth=1, pages=3, status=200, state="crawled"];
+ "https://monzo.com" -> "https://monzo.com/about" [weight=1];
+ "https://monzo.com" -> "https://monzo.com/blog" [weight=1];
+ "https://monzo.com/blog" -> "https://monzo.com" [weight=1];
+}
+`},
+		{"graphml", WriterGraphml{Cluster: 1}.Write, `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+ <key id="url" for="node" attr.name="url" attr.type="string"></key>
+ <key id="depth" for="node" attr.name="depth" attr.type="int"></key>
+ <key id="status" for="node" attr.name="status" attr.type="int"></key>
+ <key id="state" for="node" attr.name="state" attr.type="string"></key>
+ <key id="pages" for="node" attr.name="pages" attr.type="int"></key>
+ <key id="weight" for="edge" attr.name="weight" attr.type="int"></key>
+ <graph id="monzo.com" edgedefault="directed">
+  <node id="n0">
+   <data key="url">https://monzo.com</data>
+   <data key="depth">0</data>
+   <data key="status">200</data>
+   <data key="state">crawled</data>
+   <data key="pages">1</data>
+  </node>
+  <node id="n1">
+   <data key="url">https://monzo.com/about</data>
+   <data key="depth">1</data>
+   <data key="state">not_fetched</data>
+   <data key="pages">1</data>
+  </node>
+  <node id="n2">
+   <data key="url">https://monzo.com/blog</data>
+   <data key="depth">1</data>
+   <data key="status">200</data>
+   <data key="state">crawled</data>
+   <data key="pages">3</data>
+  </node>
+  <edge id="e0" source="n0" target="n1">
+   <data key="weight">1</data>
+  </edge>
+  <edge id="e1" source="n0" target="n2">
+   <data key="weight">1</data>
+  </edge>
+  <edge id="e2" source="n2" target="n0">
+   <data key="weight">1</data>
+  </edge>
+ </graph>
+</graphml>
+`},
+		{"gexf", WriterGexf{Cluster: 1}.Write, `<?xml version="1.0" encoding="UTF-8"?>
+<gexf xmlns="http://www.gexf.net/1.2draft" version="1.2">
+ <graph mode="static" defaultedgetype="directed">
+  <attributes class="node">
+   <attribute id="depth" title="depth" type="integer"></attribute>
+   <attribute id="status" title="status" type="integer"></attribute>
+   <attribute id="state" title="state" type="string"></attribute>
+   <attribute id="pages" title="pages" type="integer"></attribute>
+  </attributes>
+  <nodes>
+   <node id="n0" label="https://monzo.com">
+    <attvalues>
+     <attvalue for="depth" value="0"></attvalue>
+     <attvalue for="status" value="200"></attvalue>
+     <attvalue for="state" value="crawled"></attvalue>
+     <attvalue for="pages" value="1"></attvalue>
+    </attvalues>
+   </node>
+   <node id="n1" label="https://monzo.com/about">
+    <attvalues>
+     <attvalue for="depth" value="1"></attvalue>
+     <attvalue for="state" value="not_fetched"></attvalue>
+     <attvalue for="pages" value="1"></attvalue>
+    </attvalues>
+   </node>
+   <node id="n2" label="https://monzo.com/blog">
+    <attvalues>
+     <attvalue for="depth" value="1"></attvalue>
+     <attvalue for="status" value="200"></attvalue>
+     <attvalue for="state" value="crawled"></attvalue>
+     <attvalue for="pages" value="3"></attvalue>
+    </attvalues>
+   </node>
+  </nodes>
+  <edges>
+   <edge id="e0" source="n0" target="n1" weight="1"></edge>
+   <edge id="e1" source="n0" target="n2" weight="1"></edge>
+   <edge id="e2" source="n2" target="n0" weight="1"></edge>
+  </edges>
+ </graph>
+</gexf>
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf, getTestSite()); err != nil {
+				t.Errorf("Write() error = %v", err)
+				return
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Write() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// getTestSite return crawled site with blog section,
+// failed and not fetched pages
+func getTestSite() *site.Site {
+	url, _ := site.ParseRequestURI("https://monzo.com")
+	s := site.NewSite(url)
+	s.PageTree.State = site.Crawled
+	s.PageTree.Response = &site.Response{Status: 200}
+
+	blog, _ := s.PageTree.AddSubPage("/blog")
+	blog.State = site.Crawled
+	blog.Response = &site.Response{Status: 200}
+	about, _ := s.PageTree.AddSubPage("/about")
+	about.State = site.NotFetched
+
+	a, _ := blog.AddSubPage("/blog/a")
+	a.State = site.Crawled
+	a.Response = &site.Response{Status: 200}
+	b, _ := blog.AddSubPage("/blog/b")
+	b.State = site.Failed
+	b.Response = &site.Response{Status: 404}
+	blog.AddSubPage("/")
+	a.AddSubPage("/blog/b")
+
+	for _, page := range []*site.Page{blog, about, a, b} {
+		s.HashMap[page.Url.String()] = page
+	}
+	return s
+}
@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// WriterGraphml represent GraphML implementation of the IWriter interface
+type WriterGraphml struct {
+	Cluster int // number of url path segments to collapse pages by, 0 - no clustering
+}
+
+// graphml represent GraphML document root element
+type graphml struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphmlKey `xml:"key"`
+	Graph   graphmlGraph `xml:"graph"`
+}
+
+// graphmlKey represent declaration of nodes and edges attribute
+type graphmlKey struct {
+	Id   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+// graphmlGraph represent GraphML graph element
+type graphmlGraph struct {
+	Id          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphmlNode `xml:"node"`
+	Edges       []graphmlEdge `xml:"edge"`
+}
+
+// graphmlNode represent GraphML node element
+type graphmlNode struct {
+	Id   string        `xml:"id,attr"`
+	Data []graphmlData `xml:"data"`
+}
+
+// graphmlEdge represent GraphML edge element
+type graphmlEdge struct {
+	Id     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphmlData `xml:"data"`
+}
+
+// graphmlData represent attribute value of node or edge
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphmlKeys is declarations of written nodes and edges attributes
+var graphmlKeys = []graphmlKey{
+	{"url", "node", "url", "string"},
+	{"depth", "node", "depth", "int"},
+	{"status", "node", "status", "int"},
+	{"state", "node", "state", "string"},
+	{"pages", "node", "pages", "int"},
+	{"weight", "edge", "weight", "int"},
+}
+
+// Write writes links graph of providing site to given writer
+func (w WriterGraphml) Write(out io.Writer, data interface{}) error {
+	graph, err := NewGraph(data, w.Cluster)
+	if err != nil {
+		return err
+	}
+
+	doc := graphml{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphmlKeys,
+		Graph: graphmlGraph{Id: graph.Name, EdgeDefault: "directed"},
+	}
+	for _, node := range graph.Nodes {
+		values := []graphmlData{
+			{"url", node.Url},
+			{"depth", strconv.Itoa(node.Depth)},
+		}
+		if node.Status != 0 {
+			values = append(values, graphmlData{"status", strconv.Itoa(node.Status)})
+		}
+		if node.State != "" {
+			values = append(values, graphmlData{"state", node.State})
+		}
+		values = append(values, graphmlData{"pages", strconv.Itoa(node.Pages)})
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphmlNode{Id: node.Id, Data: values})
+	}
+	for _, edge := range graph.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphmlEdge{
+			Id:     edge.Id,
+			Source: edge.Source.Id,
+			Target: edge.Target.Id,
+			Data:   []graphmlData{{"weight", strconv.Itoa(edge.Weight)}},
+		})
+	}
+
+	return writeXml(out, doc)
+}
+
+// writeXml writes given document with XML header to given writer
+func writeXml(out io.Writer, doc interface{}) error {
+	xmlFormat, err := xml.MarshalIndent(doc, "", " ")
+	if err != nil {
+		return err
+	}
+
+	if _, err := out.Write(append(append([]byte(xml.Header), xmlFormat...), '\n')); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package html
+
+import (
+	"errors"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+var errUnsupportedData = errors.New("html writer supports only site")
+
+// reportTemplate is parsed self-contained html report template
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": percent,
+}).Parse(reportHtml))
+
+// WriterHtml represent self-contained html report implementation of the IWriter interface
+type WriterHtml struct{}
+
+// report represent html report template data
+type report struct {
+	Site     *site.Site       // crawled site
+	Duration string           // crawling duration
+	Total    int              // total site pages
+	States   []count          // pages count by crawling state
+	Statuses []count          // pages count by response status
+	Skipped  int              // pages skipped without crawling
+	Excluded int              // pages excluded by crawl rules
+	Pages    []pageRow        // pages table rows sorted by url
+	Tree     *site.Page       // site page tree
+	Broken   site.BrokenLinks // failed pages with source pages
+}
+
+// count represent number of pages with some property value
+type count struct {
+	Name  string
+	Count int
+	Total int
+}
+
+// pageRow represent row of pages table
+type pageRow struct {
+	Url          string
+	Depth        int
+	State        string
+	Status       int
+	ContentType  string
+	ResponseTime int64
+	InLinks      int
+	OutLinks     int
+	Error        string
+}
+
+// Write writes providing site to given writer as self-contained html report
+func (WriterHtml) Write(w io.Writer, data interface{}) error {
+	s, ok := data.(*site.Site)
+	if !ok {
+		return errUnsupportedData
+	}
+	return reportTemplate.Execute(w, newReport(s))
+}
+
+// newReport create html report template data of given site
+func newReport(s *site.Site) *report {
+	pages := s.Pages()
+	r := &report{
+		Site:     s,
+		Duration: s.Duration.Round(time.Millisecond).String(),
+		Total:    len(pages),
+		Skipped:  len(s.Skipped),
+		Excluded: len(s.Excluded),
+		Tree:     s.PageTree,
+		Broken:   s.Broken,
+	}
+
+	// inbound links of every page
+	inLinks := make(map[*site.Page]int)
+	for _, link := range site.Links(pages) {
+		if link.Target != nil {
+			inLinks[link.Target]++
+		}
+	}
+
+	states := make(map[string]int)
+	statuses := make(map[string]int)
+	for _, page := range pages {
+		row := pageRow{
+			Url:      page.Url.String(),
+			Depth:    page.Depth,
+			State:    page.State.String(),
+			InLinks:  inLinks[page],
+			OutLinks: len(page.Links),
+			Error:    page.Error,
+		}
+		if page.Response != nil {
+			row.Status = page.Response.Status
+			row.ContentType = page.Response.ContentType
+			row.ResponseTime = page.Response.ResponseTime
+		}
+		r.Pages = append(r.Pages, row)
+
+		states[row.State]++
+		switch {
+		case row.Status != 0:
+			statuses[strconv.Itoa(row.Status)]++
+		case page.State == site.Failed:
+			statuses["network error"]++
+		}
+	}
+	r.States = counts(states, r.Total)
+	r.Statuses = counts(statuses, r.Total)
+
+	return r
+}
+
+// counts return sorted by name counts of given values
+func counts(values map[string]int, total int) []count {
+	result := make([]count, 0, len(values))
+	for name, n := range values {
+		result = append(result, count{Name: name, Count: n, Total: total})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// percent return share of given count in percents
+func percent(c count) string {
+	if c.Total == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(float64(c.Count)*100/float64(c.Total), 'f', 1, 64)
+}
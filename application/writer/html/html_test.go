@@ -0,0 +1,80 @@
+package html
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+func TestWriterHtml_Write(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     interface{}
+		contains []string
+		wantErr  bool
+	}{
+		{"site", getTestSite(), []string{
+			"<title>Crawl report: https://monzo.com</title>",
+			`<div class="card"><b>4</b>pages</div>`,
+			`<div class="card"><b>2</b>crawled</div>`,
+			`<div class="card"><b>1</b>failed</div>`,
+			`<div class="card"><b>1</b>broken links</div>`,
+			`<div class="card"><b>1.5s</b>duration</div>`,
+			`<tr><td>200</td><td>2</td><td><span class="bar" style="width: 50.0px"></span> 50.0%</td></tr>`,
+			`<details open><summary><a href="https://monzo.com">https://monzo.com</a> 200 <small>crawled</small></summary>`,
+			`<div class="leaf"><a href="https://monzo.com/about">https://monzo.com/about</a> <small>not_fetched</small></div>`,
+			`<tr><td><a href="https://monzo.com/blog">https://monzo.com/blog</a></td><td>1</td><td>crawled</td><td>200</td><td>text/html</td><td>120</td><td>1</td><td>1</td><td class="failed"></td></tr>`,
+			`<div><a href="https://monzo.com/blog">https://monzo.com/blog</a> (&lt;b&gt;Broken&lt;/b&gt;)</div>`,
+		}, false},
+		{"unsupported", "report", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (WriterHtml{}).Write(&buf, tt.data); (err != nil) != tt.wantErr {
+				t.Errorf("Write() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			got := buf.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("Write() = %s, want containing %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+// getTestSite return crawled site with failed
+// and not fetched pages and broken link
+func getTestSite() *site.Site {
+	url, _ := site.ParseRequestURI("https://monzo.com")
+	s := site.NewSite(url)
+	s.Duration = 1500 * time.Millisecond
+	s.PageTree.State = site.Crawled
+	s.PageTree.Response = &site.Response{Status: 200, ContentType: "text/html", ResponseTime: 80}
+
+	blog, _ := s.PageTree.AddSubPage("/blog")
+	blog.State = site.Crawled
+	blog.Response = &site.Response{Status: 200, ContentType: "text/html", ResponseTime: 120}
+	about, _ := s.PageTree.AddSubPage("/about")
+	about.State = site.NotFetched
+	broken, _ := blog.AddSubPage("/broken")
+	broken.State = site.Failed
+	broken.Error = "not found"
+	broken.Response = &site.Response{Status: 404}
+
+	for _, page := range []*site.Page{blog, about, broken} {
+		s.HashMap[page.Url.String()] = page
+	}
+	s.Broken = site.BrokenLinks{{
+		Url:     "https://monzo.com/broken",
+		Status:  404,
+		Error:   "not found",
+		Sources: []site.Source{{Url: "https://monzo.com/blog", Text: "<b>Broken</b>"}},
+	}}
+	return s
+}
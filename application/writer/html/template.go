@@ -0,0 +1,122 @@
+package html
+
+// reportHtml is self-contained html report template
+// with inline styles and scripts, without any external assets
+const reportHtml = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Crawl report: {{.Site.Url}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.5em; }
+h2 { font-size: 1.2em; margin-top: 2em; border-bottom: 1px solid #ccc; }
+.cards { display: flex; flex-wrap: wrap; gap: 1em; }
+.card { border: 1px solid #ccc; border-radius: 4px; padding: 0.5em 1em; min-width: 8em; }
+.card b { display: block; font-size: 1.5em; }
+.bar { background: #4a90d9; height: 0.8em; display: inline-block; }
+table { border-collapse: collapse; width: 100%; font-size: 0.9em; }
+th, td { border: 1px solid #ddd; padding: 0.3em 0.5em; text-align: left; }
+th { background: #f4f4f4; }
+table.sortable th { cursor: pointer; }
+.failed { color: #c00; }
+details { margin-left: 1.2em; }
+summary { cursor: pointer; }
+.leaf { margin-left: 2.4em; }
+</style>
+</head>
+<body>
+<h1>Crawl report: <a href="{{.Site.Url}}">{{.Site.Url}}</a></h1>
+{{- if .Site.Incomplete}}
+<p class="failed">Crawling was interrupted before finish, report is incomplete.</p>
+{{- end}}
+
+<h2>Summary</h2>
+<div class="cards">
+<div class="card"><b>{{.Total}}</b>pages</div>
+{{- range .States}}
+<div class="card"><b>{{.Count}}</b>{{.Name}}</div>
+{{- end}}
+<div class="card"><b>{{.Skipped}}</b>skipped</div>
+<div class="card"><b>{{.Excluded}}</b>excluded</div>
+<div class="card"><b>{{len .Broken}}</b>broken links</div>
+<div class="card"><b>{{.Duration}}</b>duration</div>
+</div>
+{{- with .Site.Limits}}
+<p>Crawl limits reached: {{range $i, $limit := .}}{{if $i}}, {{end}}{{$limit}}{{end}}</p>
+{{- end}}
+
+<h2>Status distribution</h2>
+<table>
+<tr><th>Status</th><th>Pages</th><th>Share</th></tr>
+{{- range .Statuses}}
+<tr><td>{{.Name}}</td><td>{{.Count}}</td><td><span class="bar" style="width: {{percent .}}px"></span> {{percent .}}%</td></tr>
+{{- end}}
+</table>
+
+<h2>Page tree</h2>
+{{- with .Tree}}
+{{template "node" .}}
+{{- end}}
+
+<h2>Pages</h2>
+<table class="sortable">
+<thead>
+<tr><th>Url</th><th>Depth</th><th>State</th><th>Status</th><th>Content type</th><th>Response time, ms</th><th>In links</th><th>Out links</th><th>Error</th></tr>
+</thead>
+<tbody>
+{{- range .Pages}}
+<tr><td><a href="{{.Url}}">{{.Url}}</a></td><td>{{.Depth}}</td><td>{{.State}}</td><td>{{if .Status}}{{.Status}}{{end}}</td><td>{{.ContentType}}</td><td>{{if .ResponseTime}}{{.ResponseTime}}{{end}}</td><td>{{.InLinks}}</td><td>{{.OutLinks}}</td><td class="failed">{{.Error}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+
+<h2>Broken links</h2>
+{{- if .Broken}}
+<table>
+<tr><th>Url</th><th>Status</th><th>Error</th><th>Linked from</th></tr>
+{{- range .Broken}}
+<tr><td><a href="{{.Url}}">{{.Url}}</a></td><td>{{if .Status}}{{.Status}}{{else}}network error{{end}}</td><td class="failed">{{.Error}}</td><td>
+{{- range .Sources}}
+<div><a href="{{.Url}}">{{.Url}}</a>{{if .Text}} ({{.Text}}){{end}}</div>
+{{- end}}
+</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No broken links found.</p>
+{{- end}}
+
+<script>
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  table.querySelectorAll("th").forEach(function (th, column) {
+    th.addEventListener("click", function () {
+      var body = table.tBodies[0];
+      var asc = th.dataset.order !== "asc";
+      th.dataset.order = asc ? "asc" : "desc";
+      var rows = Array.prototype.slice.call(body.rows);
+      rows.sort(function (a, b) {
+        var x = a.cells[column].textContent, y = b.cells[column].textContent;
+        var cmp = x !== "" && y !== "" && !isNaN(x) && !isNaN(y) ? x - y : x.localeCompare(y);
+        return asc ? cmp : -cmp;
+      });
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+});
+</script>
+</body>
+</html>
+{{define "node"}}
+{{- if .Links}}
+<details open><summary>{{template "page" .}}</summary>
+{{- range .Links}}
+{{template "node" .}}
+{{- end}}
+</details>
+{{- else}}
+<div class="leaf">{{template "page" .}}</div>
+{{- end}}
+{{- end}}
+{{define "page"}}<a href="{{.Url}}">{{.Url}}</a>{{with .Response}} {{.Status}}{{end}}{{with .State.String}} <small>{{.}}</small>{{end}}{{end}}
+`
@@ -2,21 +2,124 @@ package json
 
 import (
 	"encoding/json"
-	"io/ioutil"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/andskur/web-crawler/application/site"
 )
 
-// WriterJson represent Json implementation of the IWriter interface
-type WriterJson struct{}
+var errNotStarted = errors.New("json stream is not started")
+
+// WriterJson represent Json implementation of the IWriter and IStreamWriter
+// interfaces, hash map pages are written as they are crawled
+type WriterJson struct {
+	w     io.Writer  // writer of started stream
+	tree  bool       // page tree stream, tree is written at the end
+	pages int        // count of written hash map pages
+	mu    sync.Mutex // mutex for concurrent pages writing
+}
 
-// WriteTo writes providing data to given file
-func (WriterJson) WriteTo(data interface{}, fileName string) error {
+// Write writes providing data to given writer
+func (*WriterJson) Write(w io.Writer, data interface{}) error {
 	jsonFormat, err := json.MarshalIndent(data, "", " ")
 	if err != nil {
 		return err
 	}
 
-	if err := ioutil.WriteFile(fileName, jsonFormat, 0644); err != nil {
+	if _, err := w.Write(append(jsonFormat, '\n')); err != nil {
 		return err
 	}
 	return nil
 }
+
+// Begin start site stream to given writer, hash map pages already
+// crawled in given site are written first. Page tree is complete
+// only after crawling, so it is written at the end of the stream.
+func (wrt *WriterJson) Begin(w io.Writer, s *site.Site) error {
+	wrt.mu.Lock()
+	defer wrt.mu.Unlock()
+	wrt.w, wrt.tree, wrt.pages = w, s.HashMap == nil, 0
+	if wrt.tree {
+		return nil
+	}
+
+	if _, err := io.WriteString(w, "{\n \"map\": ["); err != nil {
+		return err
+	}
+
+	// pages crawled before resume from checkpoint
+	for _, page := range s.Pages() {
+		if page.State.Fetched() {
+			if err := wrt.writePage(page); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WritePage writes given fetched page to started stream
+func (wrt *WriterJson) WritePage(page *site.Page) error {
+	wrt.mu.Lock()
+	defer wrt.mu.Unlock()
+	if wrt.w == nil {
+		return errNotStarted
+	}
+	if wrt.tree {
+		return nil
+	}
+	return wrt.writePage(page)
+}
+
+// End writes pages left not fetched after crawling finish
+// or interruption with the rest of the site and close the stream
+func (wrt *WriterJson) End(s *site.Site) error {
+	wrt.mu.Lock()
+	defer wrt.mu.Unlock()
+	if wrt.w == nil {
+		return errNotStarted
+	}
+	w := wrt.w
+	defer func() {
+		wrt.w = nil
+	}()
+	if wrt.tree {
+		return wrt.Write(w, s)
+	}
+
+	for _, page := range s.Pages() {
+		if !page.State.Fetched() {
+			if err := wrt.writePage(page); err != nil {
+				return err
+			}
+		}
+	}
+
+	// rest of the site is marshaled without pages,
+	// its opening brace is replaced by end of pages array
+	rest := *s
+	rest.HashMap, rest.PageTree = nil, nil
+	jsonFormat, err := json.MarshalIndent(&rest, "", " ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(append([]byte("\n ],\n"), jsonFormat[2:]...), '\n'))
+	return err
+}
+
+// writePage writes given page as next element of pages array
+func (wrt *WriterJson) writePage(page *site.Page) error {
+	jsonFormat, err := json.MarshalIndent(site.NewHashPage(page), "  ", " ")
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n  "
+	if wrt.pages == 0 {
+		separator = "\n  "
+	}
+	wrt.pages++
+	_, err = wrt.w.Write(append([]byte(separator), jsonFormat...))
+	return err
+}
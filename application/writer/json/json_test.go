@@ -0,0 +1,84 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+func TestWriterJson_stream(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapType string
+	}{
+		{"hash", "hash"},
+		{"tree", "tree"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := getTestSite(tt.mapType)
+			missing := s.Pages()[2]
+			missing.State = site.Queued
+
+			// entry page is crawled before stream begin, missing page while crawling
+			var buf bytes.Buffer
+			wrt := &WriterJson{}
+			if err := wrt.WritePage(missing); err != errNotStarted {
+				t.Errorf("WriterJson.WritePage() error = %v, want %v", err, errNotStarted)
+			}
+			if err := wrt.Begin(&buf, s); err != nil {
+				t.Fatalf("WriterJson.Begin() error = %v", err)
+			}
+			missing.State = site.Failed
+			if err := wrt.WritePage(missing); err != nil {
+				t.Fatalf("WriterJson.WritePage() error = %v", err)
+			}
+			s.Skipped["https://monzo.com/admin"] = "disallowed by robots.txt"
+			s.TotalPages = 2
+			if err := wrt.End(s); err != nil {
+				t.Fatalf("WriterJson.End() error = %v", err)
+			}
+
+			// streamed site is read back the same as written at once
+			var streamed site.Site
+			if err := json.Unmarshal(buf.Bytes(), &streamed); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v, output %s", err, buf.String())
+			}
+			var got, want bytes.Buffer
+			wrt.Write(&got, &streamed)
+			wrt.Write(&want, s)
+			if got.String() != want.String() {
+				t.Errorf("WriterJson stream = %s, want %s", got.String(), want.String())
+			}
+		})
+	}
+}
+
+// getTestSite return site of given map type with crawled
+// entry page, failed and not fetched pages
+func getTestSite(mapType string) *site.Site {
+	url, _ := site.ParseRequestURI("https://monzo.com")
+	s := site.NewSite(url)
+	s.PageTree.State = site.Crawled
+	s.PageTree.Attempts = 1
+	s.PageTree.Response = &site.Response{Status: 200, FinalUrl: "https://monzo.com", ContentLength: 22, ResponseTime: 120}
+
+	about, _ := s.PageTree.AddSubPage("/about")
+	about.State = site.NotFetched
+	missing, _ := s.PageTree.AddSubPage("/missing")
+	missing.State = site.Failed
+	missing.Attempts = 1
+	missing.Error = "server responded 404 Not Found"
+
+	switch mapType {
+	case "hash":
+		s.HashMap[about.Url.String()] = about
+		s.HashMap[missing.Url.String()] = missing
+		s.PageTree = nil
+	default:
+		s.HashMap = nil
+	}
+	return s
+}
@@ -0,0 +1,228 @@
+package junit
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+var errUnsupportedData = errors.New("junit writer supports only site and broken links report")
+
+// Failure types of test cases
+const (
+	failureFetch  = "fetch_failed" // page responded error status or failed at network level
+	failureBroken = "broken_links" // page links to broken pages
+)
+
+// WriterJunit represent JUnit XML implementation of the IWriter interface
+type WriterJunit struct{}
+
+// Write writes providing site or broken links report
+// to given writer as JUnit XML test suites
+func (WriterJunit) Write(w io.Writer, data interface{}) error {
+	var suites testSuites
+	switch data := data.(type) {
+	case *site.Site:
+		suites = siteSuites(data)
+	case *site.BrokenLinksReport:
+		suites = reportSuites(data)
+	default:
+		return errUnsupportedData
+	}
+
+	xmlFormat, err := xml.MarshalIndent(suites, "", " ")
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(append([]byte(xml.Header), xmlFormat...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// testSuites represent JUnit XML root element
+type testSuites struct {
+	XMLName  xml.Name    `xml:"testsuites"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Suites   []testSuite `xml:"testsuite"`
+}
+
+// testSuite represent JUnit XML test suite of one site
+type testSuite struct {
+	Name     string     `xml:"name,attr"`
+	Tests    int        `xml:"tests,attr"`
+	Failures int        `xml:"failures,attr"`
+	Errors   int        `xml:"errors,attr"`
+	Skipped  int        `xml:"skipped,attr"`
+	Cases    []testCase `xml:"testcase"`
+}
+
+// testCase represent JUnit XML test case of one page
+type testCase struct {
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr"`
+	Time      float64  `xml:"time,attr"`
+	Failure   *failure `xml:"failure,omitempty"`
+	Skipped   *skipped `xml:"skipped,omitempty"`
+}
+
+// failure represent failed test case details
+type failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",chardata"`
+}
+
+// skipped represent skipped test case reason
+type skipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// siteSuites create test suites with every fetched site page as a test case:
+// failed pages and pages linking to them are failed test cases,
+// not fetched and skipped pages are skipped test cases
+func siteSuites(s *site.Site) testSuites {
+	suite := testSuite{Name: s.Url.String()}
+	pages := s.Pages()
+
+	// index of failed pages for outbound links check
+	failed := make(map[string]*site.Page)
+	for _, page := range pages {
+		if page.State == site.Failed {
+			failed[page.Key()] = page
+		}
+	}
+
+	anchors := s.Anchors()
+	for _, page := range pages {
+		tc := testCase{Name: page.Url.String(), ClassName: s.Url.Host}
+		if page.Response != nil {
+			tc.Time = float64(page.Response.ResponseTime) / 1000
+		}
+
+		switch page.State {
+		case site.Failed:
+			tc.Failure = fetchFailure(page.Url.String(), page.Error, s.PageSources(page.Url.String()))
+		case site.NotFetched, site.Queued:
+			tc.Skipped = &skipped{Message: "page is not fetched because of crawl limits or interruption"}
+		default:
+			tc.Failure = brokenLinks(anchors, page, failed)
+		}
+		suite.add(tc)
+	}
+
+	// pages skipped without fetching
+	for _, skippedPage := range sortedSkipped(s.Skipped) {
+		suite.add(testCase{
+			Name:      skippedPage[0],
+			ClassName: s.Url.Host,
+			Skipped:   &skipped{Message: skippedPage[1]},
+		})
+	}
+
+	return newSuites(suite)
+}
+
+// reportSuites create test suites with every
+// broken link from report as a failed test case
+func reportSuites(report *site.BrokenLinksReport) testSuites {
+	suite := testSuite{Name: report.Url.String()}
+	for _, link := range report.Links {
+		suite.add(testCase{
+			Name:      link.Url,
+			ClassName: report.Url.Host,
+			Failure:   fetchFailure(link.Url, link.Error, link.Sources),
+		})
+	}
+	return newSuites(suite)
+}
+
+// brokenLinks return failure of given page if it links to failed pages
+func brokenLinks(anchors site.Anchors, page *site.Page, failed map[string]*site.Page) *failure {
+	var lines []string
+	for _, link := range page.Links {
+		target, ok := failed[link.Key()]
+		if !ok {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("broken link %s (%q) on %s: %s",
+			link.Url, anchors.Text(page.Url.String(), link.Url.String()), page.Url, target.Error))
+	}
+
+	switch len(lines) {
+	case 0:
+		return nil
+	case 1:
+		return &failure{Message: lines[0], Type: failureBroken, Details: lines[0]}
+	default:
+		return &failure{
+			Message: fmt.Sprintf("%d broken links on %s", len(lines), page.Url),
+			Type:    failureBroken,
+			Details: strings.Join(lines, "\n"),
+		}
+	}
+}
+
+// fetchFailure create failure of failed page with source pages linking to it
+func fetchFailure(url, err string, sources []site.Source) *failure {
+	message := fmt.Sprintf("%s: %s", url, err)
+	if len(sources) > 0 {
+		message += fmt.Sprintf(", linked from %s", sources[0].Url)
+		if len(sources) > 1 {
+			message += fmt.Sprintf(" and %d more pages", len(sources)-1)
+		}
+	}
+
+	lines := make([]string, 0, len(sources))
+	for _, source := range sources {
+		lines = append(lines, fmt.Sprintf("linked from %s (%q)", source.Url, source.Text))
+	}
+	return &failure{Message: message, Type: failureFetch, Details: strings.Join(lines, "\n")}
+}
+
+// sortedSkipped return skipped pages urls and reasons sorted by url
+func sortedSkipped(pages site.SkippedPages) [][2]string {
+	urls := make([]string, 0, len(pages))
+	for url := range pages {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	sorted := make([][2]string, 0, len(urls))
+	for _, url := range urls {
+		sorted = append(sorted, [2]string{url, pages[url]})
+	}
+	return sorted
+}
+
+// add add test case to suite and update suite counters
+func (s *testSuite) add(tc testCase) {
+	s.Tests++
+	switch {
+	case tc.Failure != nil:
+		s.Failures++
+	case tc.Skipped != nil:
+		s.Skipped++
+	}
+	s.Cases = append(s.Cases, tc)
+}
+
+// newSuites create root element with given suite
+func newSuites(suite testSuite) testSuites {
+	return testSuites{
+		Name:     "web-crawler",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suites:   []testSuite{suite},
+	}
+}
@@ -0,0 +1,87 @@
+package junit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+func TestWriterJunit_Write(t *testing.T) {
+	s := getTestSite()
+	s.FindBrokenLinks()
+
+	tests := []struct {
+		name    string
+		data    interface{}
+		want    string
+		wantErr bool
+	}{
+		{"site", s, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="web-crawler" tests="5" failures="2" skipped="2">
+ <testsuite name="https://monzo.com" tests="5" failures="2" errors="0" skipped="2">
+  <testcase name="https://monzo.com" classname="monzo.com" time="0.12">
+   <failure message="broken link https://monzo.com/missing (&#34;Missing&#34;) on https://monzo.com: server responded 404 Not Found" type="broken_links">broken link https://monzo.com/missing (&#34;Missing&#34;) on https://monzo.com: server responded 404 Not Found</failure>
+  </testcase>
+  <testcase name="https://monzo.com/about" classname="monzo.com" time="0"></testcase>
+  <testcase name="https://monzo.com/deep" classname="monzo.com" time="0">
+   <skipped message="page is not fetched because of crawl limits or interruption"></skipped>
+  </testcase>
+  <testcase name="https://monzo.com/missing" classname="monzo.com" time="0.03">
+   <failure message="https://monzo.com/missing: server responded 404 Not Found, linked from https://monzo.com" type="fetch_failed">linked from https://monzo.com (&#34;Missing&#34;)</failure>
+  </testcase>
+  <testcase name="https://monzo.com/admin" classname="monzo.com" time="0">
+   <skipped message="disallowed by robots.txt rule &#34;Disallow: /admin&#34;"></skipped>
+  </testcase>
+ </testsuite>
+</testsuites>`, false},
+		{"brokenLinks", site.NewBrokenLinksReport(s), `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="web-crawler" tests="1" failures="1" skipped="0">
+ <testsuite name="https://monzo.com" tests="1" failures="1" errors="0" skipped="0">
+  <testcase name="https://monzo.com/missing" classname="monzo.com" time="0">
+   <failure message="https://monzo.com/missing: server responded 404 Not Found, linked from https://monzo.com" type="fetch_failed">linked from https://monzo.com (&#34;Missing&#34;)</failure>
+  </testcase>
+ </testsuite>
+</testsuites>`, false},
+		{"unsupported", "sitemap", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (WriterJunit{}).Write(&buf, tt.data); (err != nil) != tt.wantErr {
+				t.Errorf("WriterJunit.Write() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriterJunit.Write() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// getTestSite return crawled site with broken,
+// not fetched and skipped pages
+func getTestSite() *site.Site {
+	url, _ := site.ParseRequestURI("https://monzo.com")
+	s := site.NewSite(url)
+	s.PageTree.State = site.Crawled
+	s.PageTree.Response = &site.Response{Status: 200, ResponseTime: 120}
+
+	about, _ := s.PageTree.AddSubPage("/about")
+	about.State = site.Crawled
+	s.AddPageToSite(about)
+
+	missing, _ := s.PageTree.AddSubPage("/missing")
+	missing.State = site.Failed
+	missing.Error = "server responded 404 Not Found"
+	missing.Response = &site.Response{Status: 404, ResponseTime: 30}
+	s.AddPageToSite(missing)
+	s.AddSource(missing.Url.String(), site.Source{Url: s.Url.String(), Text: "Missing"})
+
+	deep, _ := about.AddSubPage("/deep")
+	deep.State = site.NotFetched
+	s.AddPageToSite(deep)
+
+	s.SkipPage("https://monzo.com/admin", `disallowed by robots.txt rule "Disallow: /admin"`)
+	return s
+}
@@ -0,0 +1,119 @@
+package ndjson
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+var (
+	errUnsupportedData = errors.New("ndjson writer supports only site and broken links report")
+	errNotStarted      = errors.New("ndjson stream is not started")
+)
+
+// WriterNdjson represent newline delimited Json implementation
+// of the IWriter and IStreamWriter interfaces, every page
+// is written as one Json record line
+type WriterNdjson struct {
+	enc *json.Encoder // encoder of started stream
+	mu  sync.Mutex    // mutex for concurrent pages writing
+}
+
+// Record represent one line of the output with page crawling result,
+// linked pages are represented by their urls
+type Record struct {
+	Url      string         `json:"url"`                // Page Url
+	Depth    int            `json:"depth"`              // Distance from site entry page
+	State    site.PageState `json:"state"`              // Page crawling state
+	Attempts int            `json:"attempts,omitempty"` // Number of page fetch attempts
+	Error    string         `json:"error,omitempty"`    // Final page fetch error
+	Response *site.Response `json:"response,omitempty"` // Page fetching result
+	Links    []string       `json:"links,omitempty"`    // Urls of valid links in page
+}
+
+// NewRecord create output record of given page
+func NewRecord(page *site.Page) *Record {
+	record := &Record{
+		Url:      page.Url.String(),
+		Depth:    page.Depth,
+		State:    page.State,
+		Attempts: page.Attempts,
+		Error:    page.Error,
+		Response: page.Response,
+	}
+	for _, link := range page.Links {
+		record.Links = append(record.Links, link.Url.String())
+	}
+	return record
+}
+
+// Write writes every page of providing site or every link
+// of providing broken links report to given writer as Json lines
+func (*WriterNdjson) Write(w io.Writer, data interface{}) error {
+	enc := json.NewEncoder(w)
+	switch data := data.(type) {
+	case *site.Site:
+		for _, page := range data.Pages() {
+			if err := enc.Encode(NewRecord(page)); err != nil {
+				return err
+			}
+		}
+	case *site.BrokenLinksReport:
+		for _, link := range data.Links {
+			if err := enc.Encode(link); err != nil {
+				return err
+			}
+		}
+	default:
+		return errUnsupportedData
+	}
+	return nil
+}
+
+// Begin start pages stream to given writer,
+// pages already crawled in given site are written first
+func (wrt *WriterNdjson) Begin(w io.Writer, s *site.Site) error {
+	wrt.mu.Lock()
+	wrt.enc = json.NewEncoder(w)
+	wrt.mu.Unlock()
+
+	// pages crawled before resume from checkpoint
+	for _, page := range s.Pages() {
+		if page.State.Fetched() {
+			if err := wrt.WritePage(page); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WritePage writes given fetched page to started stream
+func (wrt *WriterNdjson) WritePage(page *site.Page) error {
+	wrt.mu.Lock()
+	defer wrt.mu.Unlock()
+	if wrt.enc == nil {
+		return errNotStarted
+	}
+	return wrt.enc.Encode(NewRecord(page))
+}
+
+// End writes pages left not fetched after crawling finish
+// or interruption and close the stream
+func (wrt *WriterNdjson) End(s *site.Site) error {
+	for _, page := range s.Pages() {
+		if !page.State.Fetched() {
+			if err := wrt.WritePage(page); err != nil {
+				return err
+			}
+		}
+	}
+
+	wrt.mu.Lock()
+	wrt.enc = nil
+	wrt.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,93 @@
+package ndjson
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+func TestWriterNdjson_Write(t *testing.T) {
+	s := getTestSite()
+	s.FindBrokenLinks()
+
+	tests := []struct {
+		name    string
+		data    interface{}
+		want    string
+		wantErr bool
+	}{
+		{"site", s, `{"url":"https://monzo.com","depth":0,"state":"crawled","attempts":1,"response":{"status":200,"final_url":"https://monzo.com","content_length":22,"response_time_ms":120},"links":["https://monzo.com/about","https://monzo.com/missing"]}
+{"url":"https://monzo.com/about","depth":1,"state":"not_fetched"}
+{"url":"https://monzo.com/missing","depth":1,"state":"failed","attempts":1,"error":"server responded 404 Not Found"}
+`, false},
+		{"brokenLinks", site.NewBrokenLinksReport(s), `{"url":"https://monzo.com/missing","error":"server responded 404 Not Found","sources":[{"url":"https://monzo.com","text":"Missing"}]}
+`, false},
+		{"unsupported", "sitemap", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (&WriterNdjson{}).Write(&buf, tt.data); (err != nil) != tt.wantErr {
+				t.Errorf("WriterNdjson.Write() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriterNdjson.Write() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterNdjson_stream(t *testing.T) {
+	s := getTestSite()
+	missing := s.HashMap["https://monzo.com/missing"]
+	missing.State = site.Queued
+
+	// entry page is crawled before stream begin, missing page while crawling
+	var buf bytes.Buffer
+	wrt := &WriterNdjson{}
+	if err := wrt.WritePage(missing); err != errNotStarted {
+		t.Errorf("WriterNdjson.WritePage() error = %v, want %v", err, errNotStarted)
+	}
+	if err := wrt.Begin(&buf, s); err != nil {
+		t.Fatalf("WriterNdjson.Begin() error = %v", err)
+	}
+	missing.State = site.Failed
+	if err := wrt.WritePage(missing); err != nil {
+		t.Fatalf("WriterNdjson.WritePage() error = %v", err)
+	}
+	if err := wrt.End(s); err != nil {
+		t.Fatalf("WriterNdjson.End() error = %v", err)
+	}
+
+	want := `{"url":"https://monzo.com","depth":0,"state":"crawled","attempts":1,"response":{"status":200,"final_url":"https://monzo.com","content_length":22,"response_time_ms":120},"links":["https://monzo.com/about","https://monzo.com/missing"]}
+{"url":"https://monzo.com/missing","depth":1,"state":"failed","attempts":1,"error":"server responded 404 Not Found"}
+{"url":"https://monzo.com/about","depth":1,"state":"not_fetched"}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriterNdjson stream = %s, want %s", got, want)
+	}
+}
+
+// getTestSite return site with crawled entry page,
+// failed and not fetched pages
+func getTestSite() *site.Site {
+	url, _ := site.ParseRequestURI("https://monzo.com")
+	s := site.NewSite(url)
+	s.PageTree.State = site.Crawled
+	s.PageTree.Attempts = 1
+	s.PageTree.Response = &site.Response{Status: 200, FinalUrl: "https://monzo.com", ContentLength: 22, ResponseTime: 120}
+
+	about, _ := s.PageTree.AddSubPage("/about")
+	about.State = site.NotFetched
+	missing, _ := s.PageTree.AddSubPage("/missing")
+	missing.State = site.Failed
+	missing.Attempts = 1
+	missing.Error = "server responded 404 Not Found"
+
+	s.HashMap[about.Url.String()] = about
+	s.HashMap[missing.Url.String()] = missing
+	s.AddSource(missing.Url.String(), site.Source{Url: "https://monzo.com", Text: "Missing"})
+	return s
+}
@@ -0,0 +1,266 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+// sitemaps.org protocol constants
+const (
+	namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	gzipExt   = ".gz"
+)
+
+// Sitemap file limits by sitemaps.org protocol,
+// variables for testing purposes
+var (
+	maxUrls     = 50000            // maximum urls in one sitemap file
+	maxFileSize = 50 * 1024 * 1024 // maximum uncompressed sitemap file size in bytes
+)
+
+// ChangeFreq values allowed by sitemaps.org protocol
+var ChangeFreq = []string{"always", "hourly", "daily", "weekly", "monthly", "yearly", "never"}
+
+var (
+	errUnsupportedData = errors.New("sitemap writer supports only site")
+	errUrlTooLarge     = errors.New("sitemap url entry exceeds maximum file size")
+	errSplitRequired   = errors.New("sitemap exceeds one file limits, it can be split only when written to file")
+)
+
+// WriterSitemap represent sitemaps.org protocol implementation of the IWriter interface
+type WriterSitemap struct {
+	Gzip       bool   // compress sitemap files with gzip
+	ChangeFreq string // change frequency of every url, empty - omitted
+	BaseUrl    string // base url of sitemap files location for sitemap index, empty - site root
+}
+
+// urlEntry represent one <url> element of the urlset
+type urlEntry struct {
+	XMLName    xml.Name `xml:"url"`
+	Loc        string   `xml:"loc"`
+	LastMod    string   `xml:"lastmod,omitempty"`
+	ChangeFreq string   `xml:"changefreq,omitempty"`
+	Priority   string   `xml:"priority"`
+}
+
+// indexEntry represent one <sitemap> element of the sitemap index
+type indexEntry struct {
+	XMLName xml.Name `xml:"sitemap"`
+	Loc     string   `xml:"loc"`
+}
+
+// Write writes crawled pages of providing site
+// to given writer as one sitemaps.org urlset
+func (w WriterSitemap) Write(out io.Writer, data interface{}) error {
+	chunks, err := w.chunks(data)
+	if err != nil {
+		return err
+	}
+	if len(chunks) > 1 {
+		return errSplitRequired
+	}
+
+	content, err := w.compress(document(urlsetOpen, chunks[0], urlsetClose))
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(content); err != nil {
+		return err
+	}
+	return nil
+}
+
+// WriteTo writes crawled pages of providing site to given file
+// as sitemaps.org urlset, splitting it to several files
+// with sitemap index in given file if protocol limits are exceeded
+func (w WriterSitemap) WriteTo(data interface{}, fileName string) error {
+	chunks, err := w.chunks(data)
+	if err != nil {
+		return err
+	}
+
+	// all urls fit to one file
+	if len(chunks) == 1 {
+		return w.writeFile(fileName, document(urlsetOpen, chunks[0], urlsetClose))
+	}
+
+	// write every chunk to own file and sitemap index to given file
+	baseUrl := w.BaseUrl
+	if baseUrl == "" {
+		s := data.(*site.Site)
+		baseUrl = fmt.Sprintf("%s://%s/", s.Url.Scheme, s.Url.Host)
+	}
+	var sitemaps [][]byte
+	for i, chunk := range chunks {
+		chunkName := chunkFilename(fileName, i+1)
+		if err := w.writeFile(chunkName, document(urlsetOpen, chunk, urlsetClose)); err != nil {
+			return err
+		}
+
+		encoded, err := xml.MarshalIndent(indexEntry{Loc: joinUrl(baseUrl, filepath.Base(chunkName))}, " ", " ")
+		if err != nil {
+			return err
+		}
+		sitemaps = append(sitemaps, encoded)
+	}
+	return w.writeFile(fileName, document(indexOpen, sitemaps, indexClose))
+}
+
+// chunks marshal url entries of providing site
+// and split them to chunks fitting sitemap file limits
+func (w WriterSitemap) chunks(data interface{}) ([][][]byte, error) {
+	s, ok := data.(*site.Site)
+	if !ok {
+		return nil, errUnsupportedData
+	}
+
+	// marshal every url entry separately for files size counting
+	var entries [][]byte
+	for _, entry := range w.urlEntries(s) {
+		encoded, err := xml.MarshalIndent(entry, " ", " ")
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, encoded)
+	}
+
+	return split(entries, len(urlsetOpen)+len(urlsetClose))
+}
+
+// Sitemap documents root elements
+var (
+	urlsetOpen  = fmt.Sprintf("<urlset xmlns=%q>\n", namespace)
+	urlsetClose = "\n</urlset>\n"
+	indexOpen   = fmt.Sprintf("<sitemapindex xmlns=%q>\n", namespace)
+	indexClose  = "\n</sitemapindex>\n"
+)
+
+// urlEntries create url entries of successfully crawled site pages
+// with unique final urls, sorted by page url
+func (w WriterSitemap) urlEntries(s *site.Site) []urlEntry {
+	var entries []urlEntry
+	seen := make(map[string]bool)
+	for _, page := range s.Pages() {
+		if page.State != site.Crawled {
+			continue
+		}
+
+		entry := urlEntry{
+			Loc:        page.Url.String(),
+			ChangeFreq: w.ChangeFreq,
+			Priority:   priority(page.Depth),
+		}
+		if page.Response != nil {
+			if page.Response.FinalUrl != "" {
+				entry.Loc = page.Response.FinalUrl
+			}
+			entry.LastMod = page.Response.LastModified
+		}
+
+		if seen[entry.Loc] {
+			continue
+		}
+		seen[entry.Loc] = true
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// priority return url priority derived from page depth:
+// 1.0 for entry page, decreased by 0.2 on every level down to 0.1
+func priority(depth int) string {
+	p := 1.0 - 0.2*float64(depth)
+	if p < 0.1 {
+		p = 0.1
+	}
+	return fmt.Sprintf("%.1f", p)
+}
+
+// split split marshaled entries to chunks fitting
+// sitemap file limits with given document overhead
+func split(entries [][]byte, overhead int) ([][][]byte, error) {
+	chunks := [][][]byte{nil}
+	size := overhead
+	for _, entry := range entries {
+		entrySize := len(entry) + 1
+		if overhead+entrySize > maxFileSize {
+			return nil, errUrlTooLarge
+		}
+
+		last := len(chunks) - 1
+		if len(chunks[last]) == maxUrls || size+entrySize > maxFileSize {
+			chunks = append(chunks, nil)
+			last++
+			size = overhead
+		}
+		chunks[last] = append(chunks[last], entry)
+		size += entrySize
+	}
+	return chunks, nil
+}
+
+// document build sitemap xml document from given root element and entries
+func document(open string, entries [][]byte, close string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(open)
+	buf.Write(bytes.Join(entries, []byte("\n")))
+	buf.WriteString(close)
+	return buf.Bytes()
+}
+
+// writeFile write given content to file, compressed if gzip is enabled
+func (w WriterSitemap) writeFile(fileName string, content []byte) error {
+	content, err := w.compress(content)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(fileName, content, 0644); err != nil {
+		return err
+	}
+	return nil
+}
+
+// compress compress given content with gzip if it is enabled
+func (w WriterSitemap) compress(content []byte) ([]byte, error) {
+	if !w.Gzip {
+		return content, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// chunkFilename return name of sitemap file with given number,
+// "sitemap.xml.gz" becomes "sitemap-1.xml.gz"
+func chunkFilename(fileName string, n int) string {
+	var suffix string
+	if strings.HasSuffix(fileName, gzipExt) {
+		suffix = gzipExt
+		fileName = strings.TrimSuffix(fileName, gzipExt)
+	}
+	ext := filepath.Ext(fileName)
+	return fmt.Sprintf("%s-%d%s%s", strings.TrimSuffix(fileName, ext), n, ext, suffix)
+}
+
+// joinUrl join base url and file name with one slash
+func joinUrl(baseUrl, name string) string {
+	return strings.TrimSuffix(baseUrl, "/") + "/" + name
+}
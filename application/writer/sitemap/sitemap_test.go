@@ -0,0 +1,272 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/andskur/web-crawler/application/site"
+)
+
+func TestWriterSitemap_WriteTo(t *testing.T) {
+	tests := []struct {
+		name    string
+		writer  WriterSitemap
+		data    interface{}
+		want    map[string]string
+		wantErr bool
+	}{
+		{"urlset", WriterSitemap{ChangeFreq: "weekly"}, getTestSite(), map[string]string{
+			"sitemap.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+ <url>
+  <loc>https://monzo.com/</loc>
+  <lastmod>2020-01-02T15:04:05Z</lastmod>
+  <changefreq>weekly</changefreq>
+  <priority>1.0</priority>
+ </url>
+ <url>
+  <loc>https://monzo.com/about</loc>
+  <changefreq>weekly</changefreq>
+  <priority>0.8</priority>
+ </url>
+ <url>
+  <loc>https://monzo.com/about/team?a=1&amp;b=2</loc>
+  <changefreq>weekly</changefreq>
+  <priority>0.6</priority>
+ </url>
+</urlset>
+`}, false},
+		{"unsupported", WriterSitemap{}, "sitemap", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := tt.writer.WriteTo(tt.data, filepath.Join(dir, "sitemap.xml")); (err != nil) != tt.wantErr {
+				t.Errorf("WriterSitemap.WriteTo() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := readFiles(t, dir, false); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WriterSitemap.WriteTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterSitemap_WriteTo_split(t *testing.T) {
+	defer func(urls int) { maxUrls = urls }(maxUrls)
+	maxUrls = 2
+
+	dir := t.TempDir()
+	w := WriterSitemap{Gzip: true, BaseUrl: "https://cdn.monzo.com/maps"}
+	if err := w.WriteTo(getTestSite(), filepath.Join(dir, "sitemap.xml.gz")); err != nil {
+		t.Fatalf("WriterSitemap.WriteTo() error = %v", err)
+	}
+
+	want := map[string]string{
+		"sitemap.xml.gz": `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+ <sitemap>
+  <loc>https://cdn.monzo.com/maps/sitemap-1.xml.gz</loc>
+ </sitemap>
+ <sitemap>
+  <loc>https://cdn.monzo.com/maps/sitemap-2.xml.gz</loc>
+ </sitemap>
+</sitemapindex>
+`,
+		"sitemap-1.xml.gz": `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+ <url>
+  <loc>https://monzo.com/</loc>
+  <lastmod>2020-01-02T15:04:05Z</lastmod>
+  <priority>1.0</priority>
+ </url>
+ <url>
+  <loc>https://monzo.com/about</loc>
+  <priority>0.8</priority>
+ </url>
+</urlset>
+`,
+		"sitemap-2.xml.gz": `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+ <url>
+  <loc>https://monzo.com/about/team?a=1&amp;b=2</loc>
+  <priority>0.6</priority>
+ </url>
+</urlset>
+`,
+	}
+	if got := readFiles(t, dir, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("WriterSitemap.WriteTo() = %v, want %v", got, want)
+	}
+}
+
+func TestWriterSitemap_Write(t *testing.T) {
+	defer func(urls int) { maxUrls = urls }(maxUrls)
+
+	tests := []struct {
+		name    string
+		maxUrls int
+		want    string
+		wantErr bool
+	}{
+		{"urlset", 10, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+ <url>
+  <loc>https://monzo.com/</loc>
+  <lastmod>2020-01-02T15:04:05Z</lastmod>
+  <priority>1.0</priority>
+ </url>
+ <url>
+  <loc>https://monzo.com/about</loc>
+  <priority>0.8</priority>
+ </url>
+ <url>
+  <loc>https://monzo.com/about/team?a=1&amp;b=2</loc>
+  <priority>0.6</priority>
+ </url>
+</urlset>
+`, false},
+		{"splitRequired", 2, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxUrls = tt.maxUrls
+			var buf bytes.Buffer
+			if err := (WriterSitemap{}).Write(&buf, getTestSite()); (err != nil) != tt.wantErr {
+				t.Errorf("WriterSitemap.Write() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriterSitemap.Write() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_split(t *testing.T) {
+	defer func(size int) { maxFileSize = size }(maxFileSize)
+	maxFileSize = 10
+
+	entries := [][]byte{[]byte("aaa"), []byte("bbb"), []byte("ccc")}
+	tests := []struct {
+		name     string
+		entries  [][]byte
+		overhead int
+		want     [][][]byte
+		wantErr  bool
+	}{
+		{"oneFile", entries[:2], 0, [][][]byte{entries[:2]}, false},
+		{"bySize", entries, 2, [][][]byte{entries[:2], entries[2:]}, false},
+		{"tooLarge", entries, 7, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := split(tt.entries, tt.overhead)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("split() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("split() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_priority(t *testing.T) {
+	tests := []struct {
+		depth int
+		want  string
+	}{
+		{0, "1.0"},
+		{1, "0.8"},
+		{4, "0.2"},
+		{10, "0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := priority(tt.depth); got != tt.want {
+				t.Errorf("priority() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_chunkFilename(t *testing.T) {
+	tests := []struct {
+		fileName string
+		want     string
+	}{
+		{"monzo.com.xml", "monzo.com-2.xml"},
+		{"out/sitemap.xml.gz", "out/sitemap-2.xml.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			if got := chunkFilename(tt.fileName, 2); got != tt.want {
+				t.Errorf("chunkFilename() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// getTestSite return crawled site with redirected
+// entry page, failed and not fetched pages
+func getTestSite() *site.Site {
+	url, _ := site.ParseRequestURI("https://monzo.com")
+	s := site.NewSite(url)
+	s.PageTree.State = site.Crawled
+	s.PageTree.Response = &site.Response{
+		Status:       200,
+		FinalUrl:     "https://monzo.com/",
+		LastModified: "2020-01-02T15:04:05Z",
+	}
+
+	about, _ := s.PageTree.AddSubPage("/about")
+	about.State = site.Crawled
+	team, _ := about.AddSubPage("/about/team")
+	team.State = site.Crawled
+	team.Response = &site.Response{Status: 200, FinalUrl: "https://monzo.com/about/team?a=1&b=2"}
+	missing, _ := s.PageTree.AddSubPage("/missing")
+	missing.State = site.Failed
+	deep, _ := about.AddSubPage("/deep")
+	deep.State = site.NotFetched
+
+	for _, page := range []*site.Page{about, team, missing, deep} {
+		s.HashMap[page.Url.String()] = page
+	}
+	return s
+}
+
+// readFiles return content of every file in given directory by file name
+func readFiles(t *testing.T, dir string, compressed bool) map[string]string {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents := make(map[string]string)
+	for _, file := range files {
+		content, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if compressed {
+			zr, err := gzip.NewReader(bytes.NewReader(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if content, err = ioutil.ReadAll(zr); err != nil {
+				t.Fatal(err)
+			}
+		}
+		contents[file.Name()] = string(content)
+	}
+	return contents
+}
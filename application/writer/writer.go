@@ -2,27 +2,125 @@ package writer
 
 import (
 	"errors"
+	"io"
+	"os"
 
+	"github.com/andskur/web-crawler/application/site"
+	"github.com/andskur/web-crawler/application/writer/csv"
+	"github.com/andskur/web-crawler/application/writer/graph"
+	"github.com/andskur/web-crawler/application/writer/html"
 	"github.com/andskur/web-crawler/application/writer/json"
+	"github.com/andskur/web-crawler/application/writer/junit"
+	"github.com/andskur/web-crawler/application/writer/ndjson"
+	"github.com/andskur/web-crawler/application/writer/sitemap"
 	"github.com/andskur/web-crawler/application/writer/xml"
 )
 
 var ErrUnsupportedWriter = errors.New("unsupported writer type")
 
-// IWriter represent writer data to file interface
+// Stdout is output file name of the standard output
+const Stdout = "-"
+
+// IWriter represent writer data to io.Writer interface
 type IWriter interface {
+	Write(w io.Writer, data interface{}) error
+}
+
+// IFileWriter represent writer, which creates
+// output files by itself, e.g. split sitemap files
+type IFileWriter interface {
 	WriteTo(data interface{}, fileName string) error
 }
 
+// IStreamWriter represent incremental writer interface,
+// site pages are written to io.Writer as they are crawled
+type IStreamWriter interface {
+	Begin(w io.Writer, s *site.Site) error // start output, already crawled pages are written
+	WritePage(page *site.Page) error       // write fetched page, safe for concurrent use
+	End(s *site.Site) error                // write rest of the site and finish output
+}
+
+// Options represent optional writers parameters
+type Options struct {
+	Gzip       bool   // compress sitemap files with gzip
+	ChangeFreq string // sitemap urls change frequency, empty - omitted
+	BaseUrl    string // base url of sitemap files location, empty - site root
+	Cluster    int    // number of url path segments to collapse graph nodes by, 0 - no clustering
+}
+
 // NewWriter create new writer instance
-func NewWriter(wtype Format) (wrt IWriter, err error) {
+func NewWriter(wtype Format, opts Options) (wrt IWriter, err error) {
 	switch wtype {
 	case JSON:
-		wrt = json.WriterJson{}
+		wrt = &json.WriterJson{}
 	case XML:
-		wrt = xml.WriterXml{}
+		wrt = &xml.WriterXml{}
+	case JUNIT:
+		wrt = junit.WriterJunit{}
+	case SITEMAP:
+		wrt = sitemap.WriterSitemap{Gzip: opts.Gzip, ChangeFreq: opts.ChangeFreq, BaseUrl: opts.BaseUrl}
+	case NDJSON:
+		wrt = &ndjson.WriterNdjson{}
+	case CSV:
+		wrt = csv.WriterCsv{Comma: ','}
+	case TSV:
+		wrt = csv.WriterCsv{Comma: '\t'}
+	case DOT:
+		wrt = graph.WriterDot{Cluster: opts.Cluster}
+	case GRAPHML:
+		wrt = graph.WriterGraphml{Cluster: opts.Cluster}
+	case GEXF:
+		wrt = graph.WriterGexf{Cluster: opts.Cluster}
+	case HTML:
+		wrt = html.WriterHtml{}
 	default:
 		err = ErrUnsupportedWriter
 	}
 	return
 }
+
+// WriteFile writes given data to file or
+// standard output with given writer
+func WriteFile(wrt IWriter, data interface{}, fileName string) error {
+	if fileWriter, ok := wrt.(IFileWriter); ok && fileName != Stdout {
+		return fileWriter.WriteTo(data, fileName)
+	}
+
+	file, err := Create(fileName)
+	if err != nil {
+		return err
+	}
+	if err := wrt.Write(file, data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Create create output file with given name,
+// standard output is returned for Stdout name
+func Create(fileName string) (io.WriteCloser, error) {
+	if fileName == Stdout {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.Create(fileName)
+}
+
+// nopCloser represent output, which
+// should not be closed after writing
+type nopCloser struct {
+	io.Writer
+}
+
+// Close do nothing
+func (nopCloser) Close() error {
+	return nil
+}
+
+// Name return human-readable name of output file
+func Name(fileName string) string {
+	if fileName == Stdout {
+		return "stdout"
+	}
+	return fileName
+}
@@ -1,17 +1,26 @@
 package writer
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/andskur/web-crawler/application/writer/xml"
 
+	"github.com/andskur/web-crawler/application/writer/csv"
+	"github.com/andskur/web-crawler/application/writer/graph"
+	"github.com/andskur/web-crawler/application/writer/html"
 	"github.com/andskur/web-crawler/application/writer/json"
+	"github.com/andskur/web-crawler/application/writer/junit"
+	"github.com/andskur/web-crawler/application/writer/ndjson"
+	"github.com/andskur/web-crawler/application/writer/sitemap"
 )
 
 func TestNewWriter(t *testing.T) {
 	type args struct {
 		wtype Format
+		opts  Options
 	}
 	tests := []struct {
 		name    string
@@ -19,13 +28,22 @@ func TestNewWriter(t *testing.T) {
 		wantWrt IWriter
 		wantErr bool
 	}{
-		{"getJsonWriter", args{JSON}, json.WriterJson{}, false},
-		{"getXmlWriter", args{XML}, xml.WriterXml{}, false},
-		{"invalidWriter", args{unsupported}, nil, true},
+		{"getJsonWriter", args{JSON, Options{}}, &json.WriterJson{}, false},
+		{"getXmlWriter", args{XML, Options{}}, &xml.WriterXml{}, false},
+		{"getJunitWriter", args{JUNIT, Options{}}, junit.WriterJunit{}, false},
+		{"getSitemapWriter", args{SITEMAP, Options{Gzip: true, ChangeFreq: "daily"}}, sitemap.WriterSitemap{Gzip: true, ChangeFreq: "daily"}, false},
+		{"getNdjsonWriter", args{NDJSON, Options{}}, &ndjson.WriterNdjson{}, false},
+		{"getCsvWriter", args{CSV, Options{}}, csv.WriterCsv{Comma: ','}, false},
+		{"getTsvWriter", args{TSV, Options{}}, csv.WriterCsv{Comma: '\t'}, false},
+		{"getDotWriter", args{DOT, Options{Cluster: 2}}, graph.WriterDot{Cluster: 2}, false},
+		{"getGraphmlWriter", args{GRAPHML, Options{}}, graph.WriterGraphml{}, false},
+		{"getGexfWriter", args{GEXF, Options{Cluster: 1}}, graph.WriterGexf{Cluster: 1}, false},
+		{"getHtmlWriter", args{HTML, Options{}}, html.WriterHtml{}, false},
+		{"invalidWriter", args{unsupported, Options{}}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotWrt, err := NewWriter(tt.args.wtype)
+			gotWrt, err := NewWriter(tt.args.wtype, tt.args.opts)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewWriter() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -36,3 +54,40 @@ func TestNewWriter(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		wrt      IWriter
+		fileName string
+		want     string
+		wantErr  bool
+	}{
+		{"json", &json.WriterJson{}, filepath.Join(dir, "data.json"), `{
+ "url": "https://monzo.com"
+}
+`, false},
+		{"invalidPath", &json.WriterJson{}, filepath.Join(dir, "missing", "data.json"), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string]string{"url": "https://monzo.com"}
+			if err := WriteFile(tt.wrt, data, tt.fileName); (err != nil) != tt.wantErr {
+				t.Errorf("WriteFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got, err := ioutil.ReadFile(tt.fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("WriteFile() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,21 +2,152 @@ package xml
 
 import (
 	"encoding/xml"
-	"io/ioutil"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/andskur/web-crawler/application/site"
 )
 
-// WriterJson represent Xml implementation of the IWriter interface
-type WriterXml struct{}
+var errNotStarted = errors.New("xml stream is not started")
+
+// WriterXml represent Xml implementation of the IWriter and IStreamWriter
+// interfaces, hash map pages are written as they are crawled
+type WriterXml struct {
+	w    io.Writer    // writer of started stream
+	enc  *xml.Encoder // encoder of started hash map stream
+	tree bool         // page tree stream, tree is written at the end
+	mu   sync.Mutex   // mutex for concurrent pages writing
+}
 
-// WriteTo writes providing data to given file
-func (WriterXml) WriteTo(data interface{}, fileName string) error {
+// Write writes providing data to given writer
+func (*WriterXml) Write(w io.Writer, data interface{}) error {
 	xmlFormat, err := xml.MarshalIndent(data, "", " ")
 	if err != nil {
 		return err
 	}
 
-	if err := ioutil.WriteFile(fileName, xmlFormat, 0644); err != nil {
+	if _, err := w.Write(append(xmlFormat, '\n')); err != nil {
 		return err
 	}
 	return nil
 }
+
+// Begin start site stream to given writer, hash map pages already
+// crawled in given site are written first. Page tree is complete
+// only after crawling, so it is written at the end of the stream.
+func (wrt *WriterXml) Begin(w io.Writer, s *site.Site) error {
+	wrt.mu.Lock()
+	defer wrt.mu.Unlock()
+	wrt.w, wrt.enc, wrt.tree = w, nil, s.HashMap == nil
+	if wrt.tree {
+		return nil
+	}
+
+	wrt.enc = xml.NewEncoder(w)
+	wrt.enc.Indent("", " ")
+	err := wrt.enc.EncodeToken(xml.StartElement{
+		Name: xml.Name{Local: "site"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "version"}, Value: strconv.Itoa(s.Version)}},
+	})
+	if err != nil {
+		return err
+	}
+	if err := wrt.enc.EncodeElement(s.Url, element("url")); err != nil {
+		return err
+	}
+	if err := wrt.enc.EncodeToken(element("map")); err != nil {
+		return err
+	}
+
+	// pages crawled before resume from checkpoint
+	for _, page := range s.Pages() {
+		if page.State.Fetched() {
+			if err := wrt.enc.Encode(site.NewHashPage(page)); err != nil {
+				return err
+			}
+		}
+	}
+	return wrt.enc.Flush()
+}
+
+// WritePage writes given fetched page to started stream
+func (wrt *WriterXml) WritePage(page *site.Page) error {
+	wrt.mu.Lock()
+	defer wrt.mu.Unlock()
+	if wrt.w == nil {
+		return errNotStarted
+	}
+	if wrt.tree {
+		return nil
+	}
+	if err := wrt.enc.Encode(site.NewHashPage(page)); err != nil {
+		return err
+	}
+	return wrt.enc.Flush()
+}
+
+// End writes pages left not fetched after crawling finish
+// or interruption with the rest of the site and close the stream
+func (wrt *WriterXml) End(s *site.Site) error {
+	wrt.mu.Lock()
+	defer wrt.mu.Unlock()
+	if wrt.w == nil {
+		return errNotStarted
+	}
+	w, enc := wrt.w, wrt.enc
+	wrt.w, wrt.enc = nil, nil
+	if wrt.tree {
+		return wrt.Write(w, s)
+	}
+
+	for _, page := range s.Pages() {
+		if !page.State.Fetched() {
+			if err := enc.Encode(site.NewHashPage(page)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "map"}}); err != nil {
+		return err
+	}
+
+	// rest of the site after pages, empty sections are omitted
+	if err := enc.EncodeElement(s.TotalPages, element("total_pages")); err != nil {
+		return err
+	}
+	sections := []struct {
+		name  string
+		value interface{}
+		empty bool
+	}{
+		{"skipped", s.Skipped, len(s.Skipped) == 0},
+		{"excluded", s.Excluded, len(s.Excluded) == 0},
+		{"limits_reached", s.Limits, len(s.Limits) == 0},
+		{"broken_links", s.Broken, len(s.Broken) == 0},
+		{"incomplete", s.Incomplete, !s.Incomplete},
+	}
+	for _, section := range sections {
+		if section.empty {
+			continue
+		}
+		if err := enc.EncodeElement(section.value, element(section.name)); err != nil {
+			return err
+		}
+	}
+
+	if err := enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "site"}}); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// element return start element with given name
+func element(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/andskur/web-crawler/application/diff"
+	"github.com/andskur/web-crawler/application/writer"
+	"github.com/andskur/web-crawler/application/writer/json"
+)
+
+// diffUsage constant provide diff command help message
+const diffUsage = "Usage:\n    diff {old.json} {new.json} {-flags}\nExample: ./web-crawler diff yesterday.json today.json -max-removed 10"
+
+var errInvalidDiffFormat = errors.New("invalid diff output format. Supported formats: text or json")
+
+// runDiff compare two Json or Xml crawl outputs given
+// in command-line arguments and return exit code
+func runDiff(args []string) int {
+	flagSet := flag.NewFlagSet("diff", flag.ExitOnError)
+	of := flagSet.String("of", "text", "-of {text || json} output format, human-readable text or json")
+	fn := flagSet.String("fn", writer.Stdout, "-fn {filename} filename to write output, \"-\" - stdout")
+	maxRemoved := flagSet.Int("max-removed", -1, "-max-removed {count} exit with non-zero code when more pages are removed, negative - never")
+
+	// validate arguments
+	if len(args) < 2 {
+		fmt.Println(diffUsage)
+		flagSet.PrintDefaults()
+		return diff.ExitError
+	}
+
+	// parse command-line flags
+	if err := flagSet.Parse(args[2:]); err != nil {
+		fmt.Println(err)
+		fmt.Println(diffUsage)
+		flagSet.PrintDefaults()
+		return diff.ExitError
+	}
+	if *of != "text" && *of != "json" {
+		logrus.Error(errInvalidDiffFormat)
+		return diff.ExitError
+	}
+
+	// load compared crawl outputs
+	old, err := diff.Load(args[0])
+	if err != nil {
+		logrus.Error(err)
+		return diff.ExitError
+	}
+	new, err := diff.Load(args[1])
+	if err != nil {
+		logrus.Error(err)
+		return diff.ExitError
+	}
+
+	// write changes in requested format
+	result := diff.Compare(old, new)
+	if err := writeDiff(result, *of, *fn); err != nil {
+		logrus.Error(err)
+		return diff.ExitError
+	}
+
+	return result.ExitCode(*maxRemoved)
+}
+
+// writeDiff writes diff result in given format to file or stdout
+func writeDiff(result *diff.Result, format, fileName string) error {
+	if format == "json" {
+		return writer.WriteFile(&json.WriterJson{}, result, fileName)
+	}
+
+	file, err := writer.Create(fileName)
+	if err != nil {
+		return err
+	}
+	result.Print(file)
+	return file.Close()
+}
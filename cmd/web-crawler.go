@@ -1,19 +1,27 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/andskur/web-crawler/application"
+	"github.com/andskur/web-crawler/application/check"
+	"github.com/andskur/web-crawler/application/site"
 	"github.com/andskur/web-crawler/config"
 )
 
 // usage constant provide help message
-const usage = "Usage:\n    {url} {-flags} \nExample: ./web-crawler https://monzo.com"
+const usage = "Usage:\n    {url} {-flags}\n    check {url} {-flags}\n    diff {old.json} {new.json} {-flags}\nExample: ./web-crawler https://monzo.com\n         ./web-crawler check https://monzo.com -allow known-broken.txt"
 
 var (
 	errNoTarget = errors.New("no target url provided")
@@ -22,63 +30,286 @@ var (
 // TODO - better way move it to separate package or use Cobra-like external tools
 
 func main() {
+	// "diff" subcommand compares two crawl outputs without crawling
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(runDiff(os.Args[2:]))
+	}
+
 	// initialize target argument and flags
 	var target string
 	flagSet := flag.NewFlagSet("set", flag.ExitOnError)
-	fn := flagSet.String("fn", "", "-fn {filename} filename to write output")
+	fn := flagSet.String("fn", "", "-fn {filename} filename to write output, \"-\" - stdout")
+	bl := flagSet.String("bl", "", "-bl {filename} filename to write broken links report, \"-\" - stdout")
 	mt := flagSet.String("mt", "hash", "-mt {hash || tree} sitemap type, hash map or page tree (default \"hash\")")
-	of := flagSet.String("of", "json", "-of {json || xml} output format, json or xml (default \"json\")")
-	p := flagSet.Bool("p", false, "-p parralelizm mode")
+	of := flagSet.String("of", "json", "-of {json || xml || junit || sitemap || ndjson || csv || tsv || dot || graphml || gexf || html} output format, json, xml, junit, sitemaps.org sitemap, ndjson, csv/tsv links edge list, links graph or html report (default \"json\")")
+	var outputs stringsFlag
+	flagSet.Var(&outputs, "o", "-o {format:maptype:filename} output written from the same crawl, e.g. json:tree:monzo-tree, can be repeated, replaces -fn, -mt and -of")
+	w := flagSet.Int("w", config.DefaultWorkers, "-w {count} number of concurrent crawling workers")
+	co := flagSet.String("co", "bfs", "-co {bfs || dfs} crawl order, breadth-first or depth-first")
 	v := flagSet.Bool("v", false, "-v verbose mode")
+	ua := flagSet.String("ua", "web-crawler", "-ua {user-agent} User-Agent header of requests, also matched against robots.txt rules")
+	ir := flagSet.Bool("ir", false, "-ir ignore robots.txt rules")
+	scope := flagSet.String("scope", "host", "-scope {host || www || domain || hosts} site hosts: target host, with and without www, all subdomains of registrable domain or -hosts list")
+	hosts := flagSet.String("hosts", "", "-hosts {hosts} comma-separated site hosts of hosts scope, target host is always included")
+	anyScheme := flagSet.Bool("any-scheme", false, "-any-scheme http and https urls are the same site and the same page")
+	var include, exclude stringsFlag
+	flagSet.Var(&include, "include", "-include {pattern} crawl only urls matched by glob or \"re:\" regexp pattern, e.g. /docs/, can be repeated")
+	flagSet.Var(&exclude, "exclude", "-exclude {pattern} skip urls matched by glob or \"re:\" regexp pattern, e.g. *.zip, can be repeated")
+	rulesFile := flagSet.String("rules", "", "-rules {filename} file with include and exclude rules, one \"include|exclude {pattern}\" per line")
+	md := flagSet.Int("md", 0, "-md {depth} maximum crawling depth from entry page, 0 - unlimited")
+	mp := flagSet.Int("mp", 0, "-mp {count} maximum number of fetched pages, 0 - unlimited")
+	timeout := flagSet.Duration("timeout", 0, "-timeout {duration} maximum crawling duration, e.g. 30m, 0 - unlimited")
+	grace := flagSet.Duration("grace", config.DefaultGracePeriod, "-grace {duration} time to wait in progress pages after interruption")
+	cp := flagSet.String("cp", "", "-cp {filename} crawling state file for periodic checkpoints")
+	ci := flagSet.Duration("ci", config.DefaultInterval, "-ci {duration} interval between crawling state checkpoints")
+	resume := flagSet.Bool("resume", false, "-resume resume crawling from checkpoint state file")
+	rps := flagSet.Float64("rps", 0, "-rps {rate} maximum requests per second to one host, 0 - unlimited")
+	delay := flagSet.Duration("delay", 0, "-delay {duration} minimum delay between requests to one host")
+	hc := flagSet.Int("hc", config.DefaultHostWorkers, "-hc {count} maximum concurrent requests to one host")
+	ct := flagSet.Duration("ct", config.DefaultConnectTimeout, "-ct {duration} connect timeout, 0 - unlimited")
+	rt := flagSet.Duration("rt", config.DefaultReadTimeout, "-rt {duration} read timeout, maximum time of waiting data from server, 0 - unlimited")
+	rqt := flagSet.Duration("rqt", config.DefaultRequestTimeout, "-rqt {duration} total page request timeout, 0 - unlimited")
+	var headers stringsFlag
+	flagSet.Var(&headers, "H", "-H {\"Name: value\"} extra request header, can be repeated")
+	proxy := flagSet.String("proxy", "", "-proxy {url} HTTP(S) proxy, HTTP_PROXY/HTTPS_PROXY environment is used by default")
+	ca := flagSet.String("ca", "", "-ca {filename} PEM bundle of additionally trusted CA certificates")
+	insecure := flagSet.Bool("insecure", false, "-insecure skip TLS certificate verification")
+	retries := flagSet.Int("retries", config.DefaultRetries, "-retries {count} maximum fetch attempts of page with connection errors, timeouts or 5xx responses")
+	rb := flagSet.Duration("rb", config.DefaultRetryBackoff, "-rb {duration} backoff before second fetch attempt, doubled on next ones")
+	strictSlash := flagSet.Bool("strict-slash", false, "-strict-slash urls differing by trailing slash are different pages")
+	index := flagSet.String("index", strings.Join(site.DefaultIndexFiles, ","), "-index {files} comma-separated directory index file names, urls with and without them are the same page")
+	query := flagSet.String("query", "drop", "-query {drop || keep || allow || strip} query string handling: reject links with query, keep all, keep only -query-params or strip tracking parameters")
+	queryParams := flagSet.String("query-params", "", "-query-params {params} comma-separated query parameters kept in allow mode or additionally stripped in strip mode, prefix* matches by prefix")
+	queryVariants := flagSet.Int("query-variants", config.DefaultQueryVariants, "-query-variants {count} maximum distinct query variants of one path, 0 - unlimited")
+	failOn := flagSet.String("fail-on", "broken,loop", "-fail-on {conditions} check: comma-separated issues failing the check: broken, loop, redirect, external")
+	allow := flagSet.String("allow", "", "-allow {filename} check: file with known-bad urls to ignore, one per line")
+	gz := flagSet.Bool("gzip", false, "-gzip sitemap: compress sitemap files with gzip")
+	cf := flagSet.String("changefreq", "", "-changefreq {frequency} sitemap: change frequency of urls: always, hourly, daily, weekly, monthly, yearly, never")
+	su := flagSet.String("sitemap-url", "", "-sitemap-url {url} sitemap: base url of sitemap files in sitemap index, site root by default")
+	cluster := flagSet.Int("cluster", 0, "-cluster {segments} graph: collapse pages to nodes by first url path segments, 0 - no clustering")
+
+	// "check" subcommand crawls target and exits with non-zero code on found issues
+	args := os.Args[1:]
+	checkMode := len(args) > 0 && args[0] == "check"
+	if checkMode {
+		args = args[1:]
+	}
+
+	// check mode has own exit code for errors
+	exitCode := 1
+	if checkMode {
+		exitCode = check.ExitError
+	}
+	fatal := func(err error) {
+		logrus.Error(err)
+		os.Exit(exitCode)
+	}
 
 	// validate arguments
-	if len(os.Args) < 2 {
+	if len(args) < 1 {
 		fmt.Println(usage)
 		flagSet.PrintDefaults()
-		os.Exit(1)
+		os.Exit(exitCode)
 	}
 
 	// get target from command-line argument
-	target = os.Args[1]
+	target = args[0]
 	if target == "" {
 		fmt.Println(errNoTarget)
 		fmt.Println(usage)
 		flagSet.PrintDefaults()
-		os.Exit(1)
+		os.Exit(exitCode)
 	}
 
 	// parse command-line flags
-	err := flagSet.Parse(os.Args[2:])
+	err := flagSet.Parse(args[1:])
 	if err != nil {
 		fmt.Println(err)
 		fmt.Println(usage)
 		flagSet.PrintDefaults()
-		os.Exit(1)
+		os.Exit(exitCode)
 	}
 
 	// create new Application Config from cli argument and params
 	cfg, err := config.NewConfig(target, *fn, *mt, *of, *v)
 	if err != nil {
-		logrus.Fatal(err)
+		fatal(err)
 	}
 
-	// set Semaphore channel
-	cfg.SetSemaphore(*p)
+	// set multiple outputs
+	if err := cfg.SetOutputs(outputs); err != nil {
+		fatal(err)
+	}
+
+	// set sitemap output options
+	if err := cfg.SetSitemap(*gz, *cf, *su); err != nil {
+		fatal(err)
+	}
+
+	// set url normalization options
+	if err := cfg.SetNormalizer(*strictSlash, *index); err != nil {
+		fatal(err)
+	}
+	if err := cfg.SetQuery(*query, *queryParams, *queryVariants); err != nil {
+		fatal(err)
+	}
+
+	// set graph output options
+	if err := cfg.SetGraph(*cluster); err != nil {
+		fatal(err)
+	}
+
+	// set broken links report
+	if err := cfg.SetBrokenLinks(*bl); err != nil {
+		fatal(err)
+	}
+
+	// set crawling workers
+	if err := cfg.SetWorkers(*w, *co); err != nil {
+		fatal(err)
+	}
+
+	// set robots.txt options
+	cfg.SetRobots(*ua, *ir)
+
+	// set site hosts scope
+	if err := cfg.SetScope(*scope, *hosts, *anyScheme); err != nil {
+		fatal(err)
+	}
+
+	// set include and exclude rules
+	if err := cfg.SetRules(include, exclude, *rulesFile); err != nil {
+		fatal(err)
+	}
+
+	// set crawling limits
+	if err := cfg.SetLimits(*md, *mp); err != nil {
+		fatal(err)
+	}
+
+	// set crawling timeout
+	if err := cfg.SetTimeout(*timeout, *grace); err != nil {
+		fatal(err)
+	}
+
+	// set crawling state checkpoints
+	if err := cfg.SetCheckpoint(*cp, *ci, *resume); err != nil {
+		fatal(err)
+	}
+
+	// set per-host politeness options
+	if err := cfg.SetPoliteness(*rps, *delay, *hc); err != nil {
+		fatal(err)
+	}
+
+	// set HTTP client options
+	if err := cfg.SetClient(*ct, *rt, *rqt, headers, *proxy, *ca, *insecure); err != nil {
+		fatal(err)
+	}
+
+	// set fetch retry policy
+	if err := cfg.SetRetries(*retries, *rb); err != nil {
+		fatal(err)
+	}
+
+	// set check mode conditions and allowlist
+	if checkMode {
+		if err := cfg.SetCheck(*failOn, *allow); err != nil {
+			fatal(err)
+		}
+	}
 
 	// create new Application with Config
 	app, err := application.NewApplication(cfg)
 	if err != nil {
-		logrus.Fatal(err)
+		fatal(err)
+	}
+
+	// stop crawling on timeout or interruption signal
+	ctx, cancel := crawlingContext(cfg.Timeout, cfg.Progress())
+	defer cancel()
+
+	// output is written in check mode only if filename or outputs are given
+	writeOutput := !checkMode || *fn != "" || len(outputs) > 0
+
+	// start writing pages while crawling, if output format supports it
+	if writeOutput {
+		if err := app.BeginOutput(); err != nil {
+			fatal(err)
+		}
 	}
 
 	// start Crawling
-	if err := app.StartCrawling(); err != nil {
-		logrus.Fatal(err)
+	if err := app.StartCrawling(ctx); err != nil {
+		fatal(err)
+	}
+
+	// check crawled site before output formatting
+	var result *check.Result
+	if checkMode {
+		result = app.RunCheck()
 	}
 
 	// format Crawler output and write it to file
-	if err := app.WriteOutput(); err != nil {
-		logrus.Fatal(err)
+	if writeOutput {
+		if err := app.WriteOutput(); err != nil {
+			fatal(err)
+		}
+	}
+
+	// write broken links report
+	if err := app.WriteBrokenLinks(); err != nil {
+		fatal(err)
+	}
+
+	// print check summary and exit with check result code
+	if checkMode {
+		result.Print(cfg.Progress())
+		cancel()
+		os.Exit(result.ExitCode())
+	}
+}
+
+// stringsFlag is repeatable command-line flag of strings
+type stringsFlag []string
+
+// String return flag values as a string
+func (h *stringsFlag) String() string {
+	return strings.Join(*h, ", ")
+}
+
+// Set add value to flag values list
+func (h *stringsFlag) Set(value string) error {
+	*h = append(*h, value)
+	return nil
+}
+
+// crawlingContext create crawling context which is canceled
+// after given timeout or on first SIGINT/SIGTERM signal,
+// second signal terminates application immediately
+func crawlingContext(timeout time.Duration, progress io.Writer) (context.Context, context.CancelFunc) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	switch {
+	case timeout > 0:
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	default:
+		ctx, cancel = context.WithCancel(context.Background())
 	}
 
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			fmt.Fprintln(progress, "\nInterrupted, finishing pages in progress...")
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+
+	return ctx, cancel
 }
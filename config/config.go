@@ -1,21 +1,115 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"runtime"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/andskur/web-crawler/application/check"
+	"github.com/andskur/web-crawler/application/client"
+	"github.com/andskur/web-crawler/application/crawler"
+	"github.com/andskur/web-crawler/application/rules"
 	"github.com/andskur/web-crawler/application/site"
 	"github.com/andskur/web-crawler/application/writer"
+	"github.com/andskur/web-crawler/application/writer/sitemap"
 )
 
+// Default crawling parameters
+const (
+	DefaultWorkers     = 10               // number of concurrent crawling workers
+	DefaultGracePeriod = 10 * time.Second // time to wait in progress pages after interruption
+	DefaultInterval    = time.Minute      // interval between crawling state checkpoints
+	DefaultHostWorkers = 4                // maximum concurrent requests to one host
+
+	DefaultConnectTimeout = 10 * time.Second // maximum time of connection establishing
+	DefaultReadTimeout    = 30 * time.Second // maximum time of waiting data from server
+	DefaultRequestTimeout = time.Minute      // maximum total page request duration
+
+	DefaultRetries      = 3           // maximum fetch attempts of page with transient errors
+	DefaultRetryBackoff = time.Second // backoff before second fetch attempt
+
+	DefaultQueryVariants = 100 // maximum distinct query variants of one path
+)
+
+var (
+	errInvalidWorkers    = errors.New("workers count should be positive")
+	errInvalidLimit      = errors.New("crawl limit should not be negative")
+	errInvalidTimeout    = errors.New("timeout should not be negative")
+	errInvalidInterval   = errors.New("checkpoint interval should be positive")
+	errInvalidRate       = errors.New("rate limit and delay should not be negative")
+	errInvalidHost       = errors.New("host workers count should be positive")
+	errInvalidHeader     = errors.New("header should be in \"Name: value\" format")
+	errInvalidProxy      = errors.New("proxy should be absolute http or https url")
+	errInvalidRetries    = errors.New("fetch attempts count should be positive")
+	errInvalidBackoff    = errors.New("retry backoff should not be negative")
+	errInvalidFreq       = fmt.Errorf("sitemap change frequency should be one of %s", strings.Join(sitemap.ChangeFreq, ", "))
+	errInvalidBaseUrl    = errors.New("sitemap base url should be absolute http or https url")
+	errUnsupportedReport = errors.New("broken links report is not supported by sitemap, graph and html output formats")
+	errInvalidCluster    = errors.New("graph cluster path segments count should not be negative")
+	errStdoutTaken       = errors.New("only one of output and broken links report can be written to stdout")
+	errInvalidOutput     = errors.New("output should be in \"format:maptype:filename\" format, map type is hash or tree")
+	errDuplicateOutput   = errors.New("outputs should be written to different files")
+	errInvalidIndex      = errors.New("index file should be file name without path")
+	errInvalidVariants   = errors.New("query variants count should not be negative")
+	errNoScopeHosts      = errors.New("hosts scope requires comma-separated list of hosts")
+)
+
+// Destination represent one output of the crawl result
+type Destination struct {
+	Format   writer.Format // output format
+	MapType  string        // type of sitemap, Page tree or Hash map
+	Filename string        // name of file for output write
+}
+
 // Config represent Crawler Application config
 type Config struct {
-	Target    *site.Url     // target web site page
-	Filename  string        // name of file for output write
-	MapType   string        // type of sitemap, Page tree or Hash map
-	Output    writer.Format // output format, Json or Xml
-	Semaphore chan int      // semaphore for Parallelization restriction
-	Verbose   bool          // verbose mode
+	Target      *site.Url     // target web site page
+	Filename    string        // name of file for output write
+	BrokenLinks string        // name of file for broken links report write, empty - no report
+	MapType     string        // type of sitemap, Page tree or Hash map
+	Output      writer.Format // output format, Json or Xml
+	Outputs     []Destination // all outputs of the crawl result, empty - only main output
+	Workers     int           // number of concurrent crawling workers
+	Order       crawler.Order // pages crawling order, breadth-first or depth-first
+	Verbose     bool          // verbose mode
+
+	UserAgent    string // user-agent for robots.txt rules matching
+	IgnoreRobots bool   // crawl pages regardless of robots.txt rules
+
+	Rules *rules.Rules // include and exclude urls rules, nil - all site urls are crawled
+	Scope *site.Scope  // site hosts scope, nil - only target host
+
+	MaxDepth int // maximum crawling depth from entry page, 0 - unlimited
+	MaxPages int // maximum number of fetched pages, 0 - unlimited
+
+	Timeout     time.Duration // maximum crawling duration, 0 - unlimited
+	GracePeriod time.Duration // time to wait in progress pages after interruption
+
+	Checkpoint string        // crawling state file name, empty - no checkpoints
+	Interval   time.Duration // interval between crawling state checkpoints
+	Resume     bool          // resume crawling from checkpoint state file
+
+	RateLimit   float64       // maximum requests per second to one host, 0 - unlimited
+	Delay       time.Duration // minimum delay between requests to one host
+	HostWorkers int           // maximum concurrent requests to one host
+
+	Client client.Options // HTTP client timeouts, headers, proxy and TLS options
+
+	Retries      int           // maximum fetch attempts of page with transient errors
+	RetryBackoff time.Duration // backoff before second fetch attempt, doubled on next ones
+
+	CheckMode bool              // check site and exit non-zero on found issues
+	FailOn    []check.Condition // issues which fail the check
+	Allowlist string            // file with known-bad urls ignored by check
+
+	WriterOptions writer.Options // sitemap and graph output formats options
+
+	Normalizer *site.Normalizer // canonical urls options of pages deduplication, nil - default one
 }
 
 // NewConfig create new config instance from given parameters
@@ -57,34 +151,411 @@ func (c *Config) setOutput(output string) (err error) {
 
 // setTarget set filename to current Config instance
 func (c *Config) setFileName(fileName string) {
-	switch fileName {
+	c.Filename = outputFilename(c.Target.Host, fileName, c.Output)
+}
+
+// outputFilename return output file name of given format,
+// site host is used without name
+func outputFilename(host, name string, format writer.Format) string {
+	switch name {
 	case "":
-		c.Filename = formatFilename(c.Target.Host, c.Output)
+		return formatFilename(host, format)
+	case writer.Stdout:
+		return writer.Stdout
 	default:
-		c.Filename = formatFilename(fileName, c.Output)
+		return formatFilename(name, format)
 	}
 }
 
-// SetSemaphore set filename to current Config instance
-func (c *Config) SetSemaphore(parralelizm bool) {
-	switch {
-	case parralelizm:
-		c.Semaphore = initCapacity(runtime.NumCPU())
-	default:
-		c.Semaphore = initCapacity(10000)
+// SetOutputs set outputs in "format:maptype:filename" format to current
+// Config instance. Map type and file name can be omitted, hash map and site
+// host are used by default. First output replaces main output.
+func (c *Config) SetOutputs(outputs []string) error {
+	if len(outputs) == 0 {
+		return nil
 	}
+
+	files := make(map[string]bool, len(outputs))
+	for _, output := range outputs {
+		parts := strings.SplitN(output, ":", 3)
+		for len(parts) < 3 {
+			parts = append(parts, "")
+		}
+
+		format, err := writer.ParseFormats(parts[0])
+		if err != nil {
+			return err
+		}
+		mapType := parts[1]
+		switch mapType {
+		case "":
+			mapType = "hash"
+		case "hash", "tree":
+		default:
+			return errInvalidOutput
+		}
+
+		dest := Destination{Format: format, MapType: mapType, Filename: outputFilename(c.Target.Host, parts[2], format)}
+		if files[dest.Filename] {
+			return errDuplicateOutput
+		}
+		files[dest.Filename] = true
+		c.Outputs = append(c.Outputs, dest)
+	}
+
+	c.Output, c.MapType, c.Filename = c.Outputs[0].Format, c.Outputs[0].MapType, c.Outputs[0].Filename
+	return nil
 }
 
-// formatFilename format filename to correct value
-func formatFilename(name string, extension writer.Format) string {
-	return fmt.Sprintf("%s.%s", name, extension)
+// Destinations return all outputs of the crawl result
+func (c *Config) Destinations() []Destination {
+	if len(c.Outputs) == 0 {
+		return []Destination{{Format: c.Output, MapType: c.MapType, Filename: c.Filename}}
+	}
+	return c.Outputs
 }
 
-// fills up a channel of integers to Semaphore capacity
-func initCapacity(maxOutstanding int) chan int {
-	ch := make(chan int, maxOutstanding)
-	for i := 0; i < maxOutstanding; i++ {
-		ch <- 1
+// SetBrokenLinks set broken links report filename to current Config instance,
+// report is written in format of the first output, every output should support it
+func (c *Config) SetBrokenLinks(fileName string) error {
+	if fileName == "" {
+		return nil
+	}
+	for _, dest := range c.Destinations() {
+		switch dest.Format {
+		case writer.SITEMAP, writer.DOT, writer.GRAPHML, writer.GEXF, writer.HTML:
+			return errUnsupportedReport
+		}
 	}
-	return ch
+	if fileName == writer.Stdout {
+		if c.stdout() {
+			return errStdoutTaken
+		}
+		c.BrokenLinks = writer.Stdout
+		return nil
+	}
+	c.BrokenLinks = formatFilename(fileName, c.Output)
+	return nil
+}
+
+// SetWorkers set crawling workers count and crawling order to current Config instance
+func (c *Config) SetWorkers(workers int, order string) (err error) {
+	if workers < 1 {
+		return errInvalidWorkers
+	}
+	c.Workers = workers
+	c.Order, err = crawler.ParseOrder(order)
+	return
+}
+
+// SetRobots set robots.txt user-agent and ignore mode to current Config instance
+func (c *Config) SetRobots(userAgent string, ignore bool) {
+	c.UserAgent = userAgent
+	c.IgnoreRobots = ignore
+}
+
+// SetScope set site hosts scope mode, comma-separated hosts of hosts
+// mode and http and https equivalence to current Config instance
+func (c *Config) SetScope(mode, hosts string, anyScheme bool) error {
+	scopeMode, err := site.ParseScopeMode(mode)
+	if err != nil {
+		return err
+	}
+
+	scope := &site.Scope{Mode: scopeMode}
+	if scopeMode == site.ScopeHosts {
+		for _, host := range strings.Split(hosts, ",") {
+			if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+				scope.Hosts = append(scope.Hosts, host)
+			}
+		}
+		if len(scope.Hosts) == 0 {
+			return errNoScopeHosts
+		}
+	}
+	if scopeMode != site.ScopeHost {
+		c.Scope = scope
+	}
+
+	// http and https pages with the same url are the same page
+	normalizer := *c.normalizer()
+	normalizer.IgnoreScheme = anyScheme
+	c.Normalizer = &normalizer
+	return nil
+}
+
+// SetRules set include and exclude urls rules from given rules file
+// and command-line patterns to current Config instance
+func (c *Config) SetRules(include, exclude []string, fileName string) (err error) {
+	crawlRules := &rules.Rules{}
+	if fileName != "" {
+		if crawlRules, err = rules.Load(fileName); err != nil {
+			return
+		}
+	}
+	for _, pattern := range include {
+		rule, err := rules.NewRule(true, pattern)
+		if err != nil {
+			return err
+		}
+		crawlRules.Add(rule)
+	}
+	for _, pattern := range exclude {
+		rule, err := rules.NewRule(false, pattern)
+		if err != nil {
+			return err
+		}
+		crawlRules.Add(rule)
+	}
+
+	if len(crawlRules.Include) > 0 || len(crawlRules.Exclude) > 0 {
+		c.Rules = crawlRules
+	}
+	return
+}
+
+// SetLimits set crawling depth and pages count limits to current Config instance
+func (c *Config) SetLimits(maxDepth, maxPages int) error {
+	if maxDepth < 0 || maxPages < 0 {
+		return errInvalidLimit
+	}
+	c.MaxDepth = maxDepth
+	c.MaxPages = maxPages
+	return nil
+}
+
+// SetTimeout set crawling timeout and interruption grace period to current Config instance
+func (c *Config) SetTimeout(timeout, gracePeriod time.Duration) error {
+	if timeout < 0 || gracePeriod < 0 {
+		return errInvalidTimeout
+	}
+	c.Timeout = timeout
+	c.GracePeriod = gracePeriod
+	return nil
+}
+
+// SetCheckpoint set crawling state file, checkpoints interval
+// and resume mode to current Config instance.
+// Default state file name is used for resuming without file name.
+func (c *Config) SetCheckpoint(fileName string, interval time.Duration, resume bool) error {
+	if interval <= 0 {
+		return errInvalidInterval
+	}
+	if fileName == "" && resume {
+		fileName = fmt.Sprintf("%s.checkpoint", c.Target.Host)
+	}
+	c.Checkpoint = fileName
+	c.Interval = interval
+	c.Resume = resume
+	return nil
+}
+
+// SetPoliteness set per-host requests rate limit, delay between
+// requests and concurrent requests cap to current Config instance
+func (c *Config) SetPoliteness(rateLimit float64, delay time.Duration, hostWorkers int) error {
+	if rateLimit < 0 || delay < 0 {
+		return errInvalidRate
+	}
+	if hostWorkers < 1 {
+		return errInvalidHost
+	}
+	c.RateLimit = rateLimit
+	c.Delay = delay
+	c.HostWorkers = hostWorkers
+	return nil
+}
+
+// SetClient set HTTP client timeouts, static headers in "Name: value" format,
+// proxy url and TLS options to current Config instance.
+// Proxy from environment is used without proxy url.
+func (c *Config) SetClient(connectTimeout, readTimeout, requestTimeout time.Duration, headers []string, proxy, caBundle string, insecure bool) error {
+	if connectTimeout < 0 || readTimeout < 0 || requestTimeout < 0 {
+		return errInvalidTimeout
+	}
+	c.Client.ConnectTimeout = connectTimeout
+	c.Client.ReadTimeout = readTimeout
+	c.Client.Timeout = requestTimeout
+
+	// parse static headers
+	c.Client.Headers = make(http.Header)
+	for _, header := range headers {
+		idx := strings.Index(header, ":")
+		if idx < 1 {
+			return errInvalidHeader
+		}
+		c.Client.Headers.Add(strings.TrimSpace(header[:idx]), strings.TrimSpace(header[idx+1:]))
+	}
+
+	// parse proxy url
+	if proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil || (proxyURL.Scheme != "http" && proxyURL.Scheme != "https") || proxyURL.Host == "" {
+			return errInvalidProxy
+		}
+		c.Client.Proxy = proxyURL
+	}
+
+	c.Client.CABundle = caBundle
+	c.Client.Insecure = insecure
+	return nil
+}
+
+// SetRetries set maximum fetch attempts and
+// base retry backoff to current Config instance
+func (c *Config) SetRetries(attempts int, backoff time.Duration) error {
+	if attempts < 1 {
+		return errInvalidRetries
+	}
+	if backoff < 0 {
+		return errInvalidBackoff
+	}
+	c.Retries = attempts
+	c.RetryBackoff = backoff
+	return nil
+}
+
+// SetCheck enable check mode with given comma-separated
+// failing conditions and allowlist file to current Config instance
+func (c *Config) SetCheck(failOn, allowlist string) (err error) {
+	c.FailOn, err = check.ParseConditions(failOn)
+	if err != nil {
+		return
+	}
+	c.CheckMode = true
+	c.Allowlist = allowlist
+	return
+}
+
+// SetSitemap set sitemap output gzip compression, urls change
+// frequency and sitemap files base url to current Config instance
+func (c *Config) SetSitemap(gzip bool, changeFreq, baseUrl string) error {
+	if changeFreq != "" && !contains(sitemap.ChangeFreq, changeFreq) {
+		return errInvalidFreq
+	}
+	if baseUrl != "" {
+		u, err := url.Parse(baseUrl)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errInvalidBaseUrl
+		}
+	}
+
+	c.WriterOptions.Gzip = gzip
+	c.WriterOptions.ChangeFreq = changeFreq
+	c.WriterOptions.BaseUrl = baseUrl
+
+	// compressed sitemap files are named with .gz suffix
+	if gzip {
+		c.Filename = gzipFilename(c.Filename, c.Output)
+		for i := range c.Outputs {
+			c.Outputs[i].Filename = gzipFilename(c.Outputs[i].Filename, c.Outputs[i].Format)
+		}
+	}
+	return nil
+}
+
+// gzipFilename return compressed sitemap file name of given output
+func gzipFilename(fileName string, format writer.Format) string {
+	if format != writer.SITEMAP || fileName == writer.Stdout || strings.HasSuffix(fileName, ".gz") {
+		return fileName
+	}
+	return fileName + ".gz"
+}
+
+// contains check if given strings slice contains string
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// SetNormalizer set trailing slash mode and comma-separated
+// directory index file names of pages deduplication
+// to current Config instance
+func (c *Config) SetNormalizer(strictSlash bool, indexFiles string) error {
+	var files []string
+	for _, file := range strings.Split(indexFiles, ",") {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+		if strings.Contains(file, "/") {
+			return errInvalidIndex
+		}
+		files = append(files, file)
+	}
+	normalizer := *c.normalizer()
+	normalizer.IgnoreTrailingSlash = !strictSlash
+	normalizer.IndexFiles = files
+	c.Normalizer = &normalizer
+	return nil
+}
+
+// SetQuery set query string handling mode, comma-separated
+// query parameters of the mode and maximum distinct query
+// variants of one path to current Config instance
+func (c *Config) SetQuery(mode, params string, maxVariants int) error {
+	if maxVariants < 0 {
+		return errInvalidVariants
+	}
+	queryMode, err := site.ParseQueryMode(mode)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, name := range strings.Split(params, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	normalizer := *c.normalizer()
+	normalizer.Query = site.QueryPolicy{Mode: queryMode, Params: names, MaxVariants: maxVariants}
+	c.Normalizer = &normalizer
+	return nil
+}
+
+// normalizer return configured urls normalizer or default one
+func (c *Config) normalizer() *site.Normalizer {
+	if c.Normalizer == nil {
+		return site.DefaultNormalizer
+	}
+	return c.Normalizer
+}
+
+// SetGraph set number of url path segments to collapse
+// graph nodes by to current Config instance
+func (c *Config) SetGraph(cluster int) error {
+	if cluster < 0 {
+		return errInvalidCluster
+	}
+	c.WriterOptions.Cluster = cluster
+	return nil
+}
+
+// Progress return output of progress messages:
+// standard error if output is written to standard output
+func (c *Config) Progress() io.Writer {
+	if c.stdout() || c.BrokenLinks == writer.Stdout {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// stdout check if any output is written to standard output
+func (c *Config) stdout() bool {
+	for _, dest := range c.Destinations() {
+		if dest.Filename == writer.Stdout {
+			return true
+		}
+	}
+	return false
+}
+
+// formatFilename format filename to correct value
+func formatFilename(name string, format writer.Format) string {
+	return fmt.Sprintf("%s.%s", name, format.Extension())
 }
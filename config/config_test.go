@@ -1,9 +1,16 @@
 package config
 
 import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/andskur/web-crawler/application/check"
 	"github.com/andskur/web-crawler/application/writer"
 
 	"github.com/andskur/web-crawler/application/site"
@@ -137,6 +144,42 @@ func TestNewConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "stdout",
+			args: args{
+				target:       "https://monzo.com",
+				fileName:     "-",
+				mapType:      "hash",
+				outputFormat: "ndjson",
+				verbose:      false,
+			},
+			want: &Config{
+				Target:   getValidURL(),
+				Filename: "-",
+				MapType:  "hash",
+				Output:   writer.NDJSON,
+				Verbose:  false,
+			},
+			wantErr: false,
+		},
+		{
+			name: "csvStdout",
+			args: args{
+				target:       "https://monzo.com",
+				fileName:     "-",
+				mapType:      "hash",
+				outputFormat: "csv",
+				verbose:      false,
+			},
+			want: &Config{
+				Target:   getValidURL(),
+				Filename: "-",
+				MapType:  "hash",
+				Output:   writer.CSV,
+				Verbose:  false,
+			},
+			wantErr: false,
+		},
 		{
 			name: "invalidFormat",
 			args: args{
@@ -168,3 +211,466 @@ func getValidURL() (validURL *site.Url) {
 	validURL, _ = site.ParseRequestURI("https://monzo.com")
 	return
 }
+
+func TestConfig_SetLimits(t *testing.T) {
+	type args struct {
+		maxDepth int
+		maxPages int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{"unlimited", args{0, 0}, false},
+		{"limited", args{3, 1000}, false},
+		{"negativeDepth", args{-1, 0}, true},
+		{"negativePages", args{0, -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			if err := c.SetLimits(tt.args.maxDepth, tt.args.maxPages); (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetLimits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_SetScope(t *testing.T) {
+	type args struct {
+		mode      string
+		hosts     string
+		anyScheme bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    *site.Scope
+		wantErr bool
+	}{
+		{"host", args{"host", "", false}, nil, false},
+		{"domain", args{"domain", "", true}, &site.Scope{Mode: site.ScopeDomain}, false},
+		{"hosts", args{"hosts", "Monzo.co.uk, community.monzo.com", false}, &site.Scope{Mode: site.ScopeHosts, Hosts: []string{"monzo.co.uk", "community.monzo.com"}}, false},
+		{"noHosts", args{"hosts", " ", false}, nil, true},
+		{"invalidMode", args{"subdomains", "", false}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			err := c.SetScope(tt.args.mode, tt.args.hosts, tt.args.anyScheme)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetScope() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(c.Scope, tt.want) {
+				t.Errorf("Config.SetScope() scope = %v, want %v", c.Scope, tt.want)
+			}
+			if c.Normalizer.IgnoreScheme != tt.args.anyScheme {
+				t.Errorf("Config.SetScope() ignore scheme = %v, want %v", c.Normalizer.IgnoreScheme, tt.args.anyScheme)
+			}
+		})
+	}
+}
+
+func TestConfig_SetRules(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.txt")
+	if err := ioutil.WriteFile(rulesFile, []byte("include /docs/\nexclude *.zip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		include  []string
+		exclude  []string
+		fileName string
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantInclude int
+		wantExclude int
+		wantErr     bool
+	}{
+		{"noRules", args{nil, nil, ""}, 0, 0, false},
+		{"flags", args{[]string{"/docs/"}, []string{"/docs/old/", "*.zip"}, ""}, 1, 2, false},
+		{"file", args{nil, []string{"/admin/"}, rulesFile}, 1, 2, false},
+		{"invalidRegexp", args{nil, []string{"re:(docs"}, ""}, 0, 0, true},
+		{"missingFile", args{nil, nil, filepath.Join(t.TempDir(), "missing.txt")}, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			err := c.SetRules(tt.args.include, tt.args.exclude, tt.args.fileName)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetRules() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantInclude+tt.wantExclude == 0 {
+				if c.Rules != nil {
+					t.Errorf("Config.SetRules() rules = %v, want nil", c.Rules)
+				}
+				return
+			}
+			if len(c.Rules.Include) != tt.wantInclude || len(c.Rules.Exclude) != tt.wantExclude {
+				t.Errorf("Config.SetRules() rules = %d include, %d exclude, want %d, %d",
+					len(c.Rules.Include), len(c.Rules.Exclude), tt.wantInclude, tt.wantExclude)
+			}
+		})
+	}
+}
+
+func TestConfig_SetPoliteness(t *testing.T) {
+	type args struct {
+		rateLimit   float64
+		delay       time.Duration
+		hostWorkers int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{"unlimited", args{0, 0, 1}, false},
+		{"limited", args{2.5, time.Second, 4}, false},
+		{"negativeRate", args{-1, 0, 1}, true},
+		{"negativeDelay", args{0, -time.Second, 1}, true},
+		{"noHostWorkers", args{0, 0, 0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			if err := c.SetPoliteness(tt.args.rateLimit, tt.args.delay, tt.args.hostWorkers); (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetPoliteness() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_SetClient(t *testing.T) {
+	type args struct {
+		timeout time.Duration
+		headers []string
+		proxy   string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    http.Header
+		wantErr bool
+	}{
+		{"default", args{time.Second, nil, ""}, http.Header{}, false},
+		{"headers", args{time.Second, []string{"Authorization: Bearer token", "X-Env:staging"}, "http://localhost:3128"},
+			http.Header{"Authorization": {"Bearer token"}, "X-Env": {"staging"}}, false},
+		{"negativeTimeout", args{-time.Second, nil, ""}, nil, true},
+		{"invalidHeader", args{time.Second, []string{"Authorization"}, ""}, nil, true},
+		{"invalidProxy", args{time.Second, nil, "localhost:3128"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			err := c.SetClient(tt.args.timeout, tt.args.timeout, tt.args.timeout, tt.args.headers, tt.args.proxy, "", false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetClient() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(c.Client.Headers, tt.want) {
+				t.Errorf("Config.SetClient() headers = %v, want %v", c.Client.Headers, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_SetRetries(t *testing.T) {
+	type args struct {
+		attempts int
+		backoff  time.Duration
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{"noRetries", args{1, 0}, false},
+		{"retries", args{3, time.Second}, false},
+		{"noAttempts", args{0, time.Second}, true},
+		{"negativeBackoff", args{3, -time.Second}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			if err := c.SetRetries(tt.args.attempts, tt.args.backoff); (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetRetries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_SetCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		failOn  string
+		want    []check.Condition
+		wantErr bool
+	}{
+		{"default", "broken,loop", []check.Condition{check.Broken, check.Loop}, false},
+		{"invalid", "broken,slow", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			if err := c.SetCheck(tt.failOn, "known-broken.txt"); (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetCheck() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(c.FailOn, tt.want) {
+				t.Errorf("Config.SetCheck() conditions = %v, want %v", c.FailOn, tt.want)
+			}
+			if c.CheckMode == tt.wantErr {
+				t.Errorf("Config.SetCheck() check mode = %v, want %v", c.CheckMode, !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_SetSitemap(t *testing.T) {
+	type args struct {
+		gzip       bool
+		changeFreq string
+		baseUrl    string
+	}
+	tests := []struct {
+		name         string
+		output       writer.Format
+		args         args
+		wantFilename string
+		wantErr      bool
+	}{
+		{"plain", writer.SITEMAP, args{false, "", ""}, "monzo.com.xml", false},
+		{"gzip", writer.SITEMAP, args{true, "daily", "https://cdn.monzo.com"}, "monzo.com.xml.gz", false},
+		{"gzipJson", writer.JSON, args{true, "", ""}, "monzo.com.xml", false},
+		{"invalidFreq", writer.SITEMAP, args{false, "sometimes", ""}, "monzo.com.xml", true},
+		{"invalidBaseUrl", writer.SITEMAP, args{false, "", "/maps"}, "monzo.com.xml", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Output: tt.output, Filename: "monzo.com.xml", Outputs: []Destination{
+				{Format: tt.output, MapType: "hash", Filename: "monzo.com.xml"},
+				{Format: writer.JSON, MapType: "tree", Filename: "monzo.com.json"},
+			}}
+			if err := c.SetSitemap(tt.args.gzip, tt.args.changeFreq, tt.args.baseUrl); (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetSitemap() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if c.Filename != tt.wantFilename {
+				t.Errorf("Config.SetSitemap() filename = %v, want %v", c.Filename, tt.wantFilename)
+			}
+			if c.Outputs[0].Filename != tt.wantFilename || c.Outputs[1].Filename != "monzo.com.json" {
+				t.Errorf("Config.SetSitemap() outputs = %v, want %v", c.Outputs, tt.wantFilename)
+			}
+		})
+	}
+}
+
+func TestConfig_SetBrokenLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   writer.Format
+		filename string
+		outputs  []Destination
+		report   string
+		want     string
+		wantErr  bool
+	}{
+		{"json", writer.JSON, "monzo.com.json", nil, "broken", "broken.json", false},
+		{"stdout", writer.JSON, "monzo.com.json", nil, "-", "-", false},
+		{"stdoutTaken", writer.JSON, "-", nil, "-", "", true},
+		{"sitemap", writer.SITEMAP, "monzo.com.xml", nil, "broken", "", true},
+		{"graph", writer.GEXF, "monzo.com.gexf", nil, "broken", "", true},
+		{"html", writer.HTML, "monzo.com.html", nil, "broken", "", true},
+		{"outputs", writer.XML, "monzo.com.xml", []Destination{
+			{Format: writer.XML, MapType: "hash", Filename: "monzo.com.xml"},
+			{Format: writer.JSON, MapType: "tree", Filename: "monzo.com.json"},
+		}, "broken", "broken.xml", false},
+		{"unsupportedOutput", writer.JSON, "monzo.com.json", []Destination{
+			{Format: writer.JSON, MapType: "hash", Filename: "monzo.com.json"},
+			{Format: writer.SITEMAP, MapType: "hash", Filename: "sitemap.xml"},
+		}, "broken", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Output: tt.output, Filename: tt.filename, Outputs: tt.outputs}
+			if err := c.SetBrokenLinks(tt.report); (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetBrokenLinks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if c.BrokenLinks != tt.want {
+				t.Errorf("Config.SetBrokenLinks() = %v, want %v", c.BrokenLinks, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Progress(t *testing.T) {
+	tests := []struct {
+		name        string
+		filename    string
+		brokenLinks string
+		want        io.Writer
+	}{
+		{"files", "monzo.com.json", "broken.json", os.Stdout},
+		{"outputStdout", "-", "broken.json", os.Stderr},
+		{"reportStdout", "monzo.com.json", "-", os.Stderr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Filename: tt.filename, BrokenLinks: tt.brokenLinks}
+			if got := c.Progress(); got != tt.want {
+				t.Errorf("Config.Progress() = %v, want %v", got, tt.want)
+			}
+
+			// same result for filename given as one of multiple outputs
+			c = &Config{BrokenLinks: tt.brokenLinks, Outputs: []Destination{
+				{Format: writer.SITEMAP, MapType: "hash", Filename: "sitemap.xml"},
+				{Format: writer.JSON, MapType: "tree", Filename: tt.filename},
+			}}
+			if got := c.Progress(); got != tt.want {
+				t.Errorf("Config.Progress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_SetGraph(t *testing.T) {
+	tests := []struct {
+		name    string
+		cluster int
+		wantErr bool
+	}{
+		{"noClustering", 0, false},
+		{"cluster", 2, false},
+		{"negative", -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			if err := c.SetGraph(tt.cluster); (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetGraph() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_SetNormalizer(t *testing.T) {
+	tests := []struct {
+		name        string
+		strictSlash bool
+		indexFiles  string
+		want        *site.Normalizer
+		wantErr     bool
+	}{
+		{"default", false, "index.html,index.htm,index.php", site.DefaultNormalizer, false},
+		{"strictSlash", true, "index.html", &site.Normalizer{IndexFiles: []string{"index.html"}}, false},
+		{"noIndexFiles", false, "", &site.Normalizer{IgnoreTrailingSlash: true}, false},
+		{"spaces", false, " index.html , default.asp ", &site.Normalizer{IgnoreTrailingSlash: true, IndexFiles: []string{"index.html", "default.asp"}}, false},
+		{"path", false, "docs/index.html", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			err := c.SetNormalizer(tt.strictSlash, tt.indexFiles)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetNormalizer() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(c.Normalizer, tt.want) {
+				t.Errorf("Config.SetNormalizer() normalizer = %v, want %v", c.Normalizer, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_SetQuery(t *testing.T) {
+	type args struct {
+		mode        string
+		params      string
+		maxVariants int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    site.QueryPolicy
+		wantErr bool
+	}{
+		{"drop", args{"drop", "", 0}, site.QueryPolicy{}, false},
+		{"allow", args{"allow", "page, sort", 50}, site.QueryPolicy{Mode: site.QueryAllow, Params: []string{"page", "sort"}, MaxVariants: 50}, false},
+		{"strip", args{"strip", "", 100}, site.QueryPolicy{Mode: site.QueryStrip, MaxVariants: 100}, false},
+		{"invalidMode", args{"all", "", 0}, site.QueryPolicy{}, true},
+		{"negativeVariants", args{"keep", "", -1}, site.QueryPolicy{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			err := c.SetQuery(tt.args.mode, tt.args.params, tt.args.maxVariants)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(c.Normalizer.Query, tt.want) {
+				t.Errorf("Config.SetQuery() policy = %v, want %v", c.Normalizer.Query, tt.want)
+			}
+			if c.Normalizer.IgnoreTrailingSlash != site.DefaultNormalizer.IgnoreTrailingSlash {
+				t.Errorf("Config.SetQuery() changed trailing slash mode")
+			}
+		})
+	}
+}
+
+func TestConfig_SetOutputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs []string
+		want    []Destination
+		wantErr bool
+	}{
+		{"mainOutput", nil, []Destination{{writer.JSON, "hash", "monzo.com.json"}}, false},
+		{"multiple", []string{"json:tree:monzo-tree", "xml:hash:monzo-hash", "sitemap::sitemap", "ndjson"}, []Destination{
+			{writer.JSON, "tree", "monzo-tree.json"},
+			{writer.XML, "hash", "monzo-hash.xml"},
+			{writer.SITEMAP, "hash", "sitemap.xml"},
+			{writer.NDJSON, "hash", "monzo.com.ndjson"},
+		}, false},
+		{"stdout", []string{"json:tree:-", "html"}, []Destination{
+			{writer.JSON, "tree", "-"},
+			{writer.HTML, "hash", "monzo.com.html"},
+		}, false},
+		{"invalidFormat", []string{"yaml:tree:monzo"}, nil, true},
+		{"invalidMapType", []string{"json:list:monzo"}, nil, true},
+		{"sameFile", []string{"json:tree", "json:hash"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := NewConfig("https://monzo.com", "", "hash", "json", false)
+			if err := c.SetOutputs(tt.outputs); (err != nil) != tt.wantErr {
+				t.Errorf("Config.SetOutputs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := c.Destinations(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config.Destinations() = %v, want %v", got, tt.want)
+			}
+			if main := tt.want[0]; c.Output != main.Format || c.MapType != main.MapType || c.Filename != main.Filename {
+				t.Errorf("Config.SetOutputs() main output = %v %v %v, want %v", c.Output, c.MapType, c.Filename, main)
+			}
+		})
+	}
+}
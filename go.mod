@@ -1,5 +1,7 @@
 module github.com/andskur/web-crawler
 
+go 1.15
+
 require (
 	github.com/sirupsen/logrus v1.3.0
 	golang.org/x/net v0.0.0-20190213061140-3a22650c66bd